@@ -0,0 +1,147 @@
+package pag
+
+import (
+	"context"
+	"net/http"
+	urlpkg "net/url"
+	"strconv"
+	"time"
+)
+
+type AlertState string
+
+const (
+	AlertStateUnprocessed AlertState = "unprocessed"
+	AlertStateActive      AlertState = "active"
+	AlertStateSuppressed  AlertState = "suppressed"
+)
+
+type AlertManagerAlertStatus struct {
+	State       AlertState `json:"state"`
+	SilencedBy  []string   `json:"silencedBy"`
+	InhibitedBy []string   `json:"inhibitedBy"`
+}
+
+type AlertManagerReceiver struct {
+	Name string `json:"name"`
+}
+
+type AlertManagerAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+
+	// The fields below are only set on alerts returned by Alertmanager.
+	Fingerprint string                   `json:"fingerprint,omitempty"`
+	UpdatedAt   *time.Time               `json:"updatedAt,omitempty"`
+	Receivers   []AlertManagerReceiver   `json:"receivers,omitempty"`
+	Status      *AlertManagerAlertStatus `json:"status,omitempty"`
+}
+
+type AlertManagerAlertGroup struct {
+	Labels   map[string]string    `json:"labels"`
+	Receiver AlertManagerReceiver `json:"receiver"`
+	Alerts   []AlertManagerAlert  `json:"alerts"`
+}
+
+// AlertFilter narrows down the alerts returned by ListAlerts and ListAlertGroups.
+// Nil flags are left to the Alertmanager defaults.
+type AlertFilter struct {
+	Active      *bool
+	Silenced    *bool
+	Inhibited   *bool
+	Unprocessed *bool
+
+	Matchers []Matcher
+
+	// Receiver is a regular expression matching receiver names.
+	Receiver string
+}
+
+func (f *AlertFilter) query() urlpkg.Values {
+	query := urlpkg.Values{}
+	if f == nil {
+		return query
+	}
+
+	flags := map[string]*bool{
+		"active":      f.Active,
+		"silenced":    f.Silenced,
+		"inhibited":   f.Inhibited,
+		"unprocessed": f.Unprocessed,
+	}
+	for name, flag := range flags {
+		if flag != nil {
+			query.Set(name, strconv.FormatBool(*flag))
+		}
+	}
+	for _, m := range f.Matchers {
+		query.Add("filter", m.String())
+	}
+	if f.Receiver != "" {
+		query.Set("receiver", f.Receiver)
+	}
+
+	return query
+}
+
+// postableAlert is the body accepted by POST /api/v2/alerts.
+type postableAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     *time.Time        `json:"startsAt,omitempty"`
+	EndsAt       *time.Time        `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+func (api *alertManagerAPI) ListAlerts(ctx context.Context, filter *AlertFilter) ([]AlertManagerAlert, error) {
+	url := api.URL("/api/v2/alerts", map[string]string{})
+	url.RawQuery = filter.query().Encode()
+
+	var out []AlertManagerAlert
+	if err := api.doJSON(ctx, http.MethodGet, url, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (api *alertManagerAPI) ListAlertGroups(ctx context.Context, filter *AlertFilter) ([]AlertManagerAlertGroup, error) {
+	url := api.URL("/api/v2/alerts/groups", map[string]string{})
+	query := filter.query()
+	// the groups endpoint has no unprocessed filter
+	query.Del("unprocessed")
+	url.RawQuery = query.Encode()
+
+	var out []AlertManagerAlertGroup
+	if err := api.doJSON(ctx, http.MethodGet, url, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PostAlerts pushes alerts to Alertmanager as if they were sent by Prometheus.
+// Zero StartsAt/EndsAt are left for Alertmanager to fill in.
+func (api *alertManagerAPI) PostAlerts(ctx context.Context, alerts ...AlertManagerAlert) error {
+	in := make([]postableAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		pa := postableAlert{
+			Labels:       alert.Labels,
+			Annotations:  alert.Annotations,
+			GeneratorURL: alert.GeneratorURL,
+		}
+		if !alert.StartsAt.IsZero() {
+			startsAt := alert.StartsAt
+			pa.StartsAt = &startsAt
+		}
+		if !alert.EndsAt.IsZero() {
+			endsAt := alert.EndsAt
+			pa.EndsAt = &endsAt
+		}
+		in = append(in, pa)
+	}
+
+	url := api.URL("/api/v2/alerts", map[string]string{})
+	return api.doJSON(ctx, http.MethodPost, url, in, nil)
+}
@@ -0,0 +1,81 @@
+package pag
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertManagerAPI_Alerts(t *testing.T) {
+	var posted []map[string]any
+	var queries = map[string]string{}
+
+	alert := AlertManagerAlert{
+		Labels:      map[string]string{"alertname": "NodeDown", "instance": "node1"},
+		Annotations: map[string]string{"summary": "node1 is down"},
+		Fingerprint: "abc",
+		Receivers:   []AlertManagerReceiver{{Name: "web.hook"}},
+		Status:      &AlertManagerAlertStatus{State: AlertStateActive},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/alerts", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+			return
+		}
+		queries[r.URL.Path] = r.URL.RawQuery
+		_ = json.NewEncoder(w).Encode([]AlertManagerAlert{alert})
+	})
+	mux.HandleFunc("/api/v2/alerts/groups", func(w http.ResponseWriter, r *http.Request) {
+		queries[r.URL.Path] = r.URL.RawQuery
+		_ = json.NewEncoder(w).Encode([]AlertManagerAlertGroup{{
+			Labels:   map[string]string{"alertname": "NodeDown"},
+			Receiver: AlertManagerReceiver{Name: "web.hook"},
+			Alerts:   []AlertManagerAlert{alert},
+		}})
+	})
+
+	api := newAlertmanagerTestAPI(t, mux)
+	ctx := context.Background()
+	silenced := false
+
+	filter := &AlertFilter{
+		Silenced:    &silenced,
+		Unprocessed: &silenced,
+		Matchers:    []Matcher{{Name: "alertname", Value: "NodeDown"}},
+		Receiver:    "web.*",
+	}
+
+	alerts, err := api.ListAlerts(ctx, filter)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "node1", alerts[0].Labels["instance"])
+	assert.Equal(t, AlertStateActive, alerts[0].Status.State)
+	assert.Equal(t, `filter=alertname%3D%22NodeDown%22&receiver=web.%2A&silenced=false&unprocessed=false`, queries["/api/v2/alerts"])
+
+	groups, err := api.ListAlertGroups(ctx, filter)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, groups, 1)
+	assert.Equal(t, "web.hook", groups[0].Receiver.Name)
+	assert.Equal(t, "abc", groups[0].Alerts[0].Fingerprint)
+	assert.Equal(t, `filter=alertname%3D%22NodeDown%22&receiver=web.%2A&silenced=false`, queries["/api/v2/alerts/groups"])
+
+	err = api.PostAlerts(ctx, alert)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, posted, 1)
+	assert.NotContains(t, posted[0], "startsAt")
+	assert.NotContains(t, posted[0], "fingerprint")
+	assert.NotContains(t, posted[0], "status")
+}
@@ -3,14 +3,20 @@ package pag
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	urlpkg "net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/model"
 	"sigs.k8s.io/yaml"
 )
@@ -24,7 +30,12 @@ type AlertManagerYAML struct {
 
 	Receivers []AlertManagerReceiverYAML `json:"receivers,omitempty"`
 
-	InhibitRule []AlertManagerInhibitRuleYAML `json:"inhibit_rule,omitempty"`
+	InhibitRule []AlertManagerInhibitRuleYAML `json:"inhibit_rules,omitempty"`
+
+	// MuteTimeIntervals is deprecated in favour of TimeIntervals, both define
+	// intervals that routes can refer to by name.
+	MuteTimeIntervals []AlertManagerTimeIntervalYAML `json:"mute_time_intervals,omitempty"`
+	TimeIntervals     []AlertManagerTimeIntervalYAML `json:"time_intervals,omitempty"`
 }
 
 type AlertManagerGlobalYAML struct {
@@ -38,25 +49,33 @@ type AlertManagerGlobalYAML struct {
 	SmtpAuthSecret       string `json:"smtp_auth_secret,omitempty"`
 	SmtpRequireTLS       bool   `json:"smtp_require_tls,omitempty"`
 
-	//SlackAPIURL     string `json:"slack_api_url,omitempty"`
-	//SlackAPIURLFile string `json:"slack_api_url_file,omitempty"`
-	//
-	//VictoropsAPIKey     string `json:"victorops_api_key,omitempty"`
-	//VictoropsAPIKeyFile string `json:"victorops_api_key_file,omitempty"`
-	//VictoropsAPIURL     string `json:"victorops_api_url,omitempty"`
-	//
-	//PagerDutyURL string `json:"pagerduty_url,omitempty"`
-	//
-	//OpsGenieAPIKey     string `json:"opsgenie_api_key,omitempty"`
-	//OpsGenieAPIKeyFile string `json:"opsgenie_api_key_file,omitempty"`
-	//OpsGenieAPIURL     string `json:"opsgenie_api_url,omitempty"`
+	SlackAPIURL     string `json:"slack_api_url,omitempty"`
+	SlackAPIURLFile string `json:"slack_api_url_file,omitempty"`
+
+	VictoropsAPIKey     string `json:"victorops_api_key,omitempty"`
+	VictoropsAPIKeyFile string `json:"victorops_api_key_file,omitempty"`
+	VictoropsAPIURL     string `json:"victorops_api_url,omitempty"`
+
+	PagerDutyURL string `json:"pagerduty_url,omitempty"`
+
+	OpsGenieAPIKey     string `json:"opsgenie_api_key,omitempty"`
+	OpsGenieAPIKeyFile string `json:"opsgenie_api_key_file,omitempty"`
+	OpsGenieAPIURL     string `json:"opsgenie_api_url,omitempty"`
 
 	WechatAPIURL    string `json:"wechat_api_url,omitempty"`
 	WechatAPISecret string `json:"wechat_api_secret,omitempty"`
 	WechatAPICorpID string `json:"wechat_api_corp_id,omitempty"`
 
-	//TelegramAPIURL string `json:"telegram_api_url,omitempty"`
-	//WebexAPIURL    string `json:"webex_api_url,omitempty"`
+	TelegramAPIURL string `json:"telegram_api_url,omitempty"`
+	WebexAPIURL    string `json:"webex_api_url,omitempty"`
+
+	JiraAPIURL string `json:"jira_api_url,omitempty"`
+
+	RocketchatAPIURL      string `json:"rocketchat_api_url,omitempty"`
+	RocketchatToken       string `json:"rocketchat_token,omitempty"`
+	RocketchatTokenFile   string `json:"rocketchat_token_file,omitempty"`
+	RocketchatTokenID     string `json:"rocketchat_token_id,omitempty"`
+	RocketchatTokenIDFile string `json:"rocketchat_token_id_file,omitempty"`
 
 	HTTPConfig *config.HTTPClientConfig `json:"http_config,omitempty"`
 
@@ -72,7 +91,7 @@ type AlertManagerRoute struct {
 	MatchRe  map[string]string `json:"match_re,omitempty"`
 	Matchers []string          `json:"matchers,omitempty"`
 
-	GroupWaits    model.Duration `json:"group_waits,omitempty"`
+	GroupWaits    model.Duration `json:"group_wait,omitempty"`
 	GroupInterval model.Duration `json:"group_interval,omitempty"`
 
 	RepeatInterval model.Duration `json:"repeat_interval,omitempty"`
@@ -84,14 +103,43 @@ type AlertManagerRoute struct {
 	Routes []*AlertManagerRoute `json:"routes,omitempty"`
 }
 
+// AlertManagerReceiverYAML is a receiver with its notification integrations.
+// Integrations for which Alertmanager sends resolved notifications by default
+// have SendResolved as a *bool, so that an explicit false is written.
 type AlertManagerReceiverYAML struct {
 	Name string `json:"name"`
 
-	EmailConfigs []ReceiverEmailConfig `json:"email_config,omitempty"`
+	EmailConfigs []ReceiverEmailConfig `json:"email_configs,omitempty"`
 
 	WebhookConfigs []ReceiverWebhookYAML `json:"webhook_configs,omitempty"`
 
 	WeChatConfigs []ReceiverWechatYAML `json:"wechat_configs,omitempty"`
+
+	SlackConfigs []ReceiverSlackYAML `json:"slack_configs,omitempty"`
+
+	PagerDutyConfigs []ReceiverPagerDutyYAML `json:"pagerduty_configs,omitempty"`
+
+	OpsGenieConfigs []ReceiverOpsGenieYAML `json:"opsgenie_configs,omitempty"`
+
+	VictorOpsConfigs []ReceiverVictorOpsYAML `json:"victorops_configs,omitempty"`
+
+	TelegramConfigs []ReceiverTelegramYAML `json:"telegram_configs,omitempty"`
+
+	WebexConfigs []ReceiverWebexYAML `json:"webex_configs,omitempty"`
+
+	DiscordConfigs []ReceiverDiscordYAML `json:"discord_configs,omitempty"`
+
+	MSTeamsConfigs []ReceiverMSTeamsYAML `json:"msteams_configs,omitempty"`
+
+	PushoverConfigs []ReceiverPushoverYAML `json:"pushover_configs,omitempty"`
+
+	SNSConfigs []ReceiverSNSYAML `json:"sns_configs,omitempty"`
+
+	JiraConfigs []ReceiverJiraYAML `json:"jira_configs,omitempty"`
+
+	RocketchatConfigs []ReceiverRocketchatYAML `json:"rocketchat_configs,omitempty"`
+
+	MSTeamsV2Configs []ReceiverMSTeamsV2YAML `json:"msteamsv2_configs,omitempty"`
 }
 
 type AlertManagerInhibitRuleYAML struct {
@@ -110,7 +158,7 @@ type ReceiverEmailConfig struct {
 	SendResolved bool   `json:"send_resolved,omitempty"`
 	To           string `json:"to"`
 	From         string `json:"from,omitempty"`
-	SmartHost    string `json:"smart_host,omitempty"`
+	SmartHost    string `json:"smarthost,omitempty"`
 	Hello        string `json:"hello,omitempty"`
 
 	AuthUsername     string `json:"auth_username,omitempty"`
@@ -119,7 +167,7 @@ type ReceiverEmailConfig struct {
 	AuthSecret       string `json:"auth_secret,omitempty"`
 	AuthIdentify     string `json:"auth_identity,omitempty"`
 
-	RequiredTLS bool `json:"required_tls,omitempty"`
+	RequiredTLS bool `json:"require_tls,omitempty"`
 
 	TlsConfig *config.TLSConfig `json:"tls_config,omitempty"`
 
@@ -130,7 +178,7 @@ type ReceiverEmailConfig struct {
 }
 
 type ReceiverWebhookYAML struct {
-	SendResolved bool                     `json:"send_resolved,omitempty"`
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
 	URL          string                   `json:"url,omitempty"`
 	URLFile      string                   `json:"url_file,omitempty"`
 	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
@@ -138,24 +186,369 @@ type ReceiverWebhookYAML struct {
 }
 
 type ReceiverWechatYAML struct {
-	SendResolved bool   `json:"send_resolved,omitempty"`
-	APISecret    string `json:"api_secret,omitempty"`
-	APIURL       string `json:"api_url,omitempty"`
-	CorpID       string `json:"corp_id,omitempty"`
-	Message      string `json:"message,omitempty"`
-	MessageType  string `json:"message_type,omitempty"`
-	AgentID      string `json:"agent_id,omitempty"`
-	ToUser       string `json:"to_user,omitempty"`
-	ToParty      string `json:"to_party,omitempty"`
-	ToTag        string `json:"to_tag,omitempty"`
+	SendResolved bool                     `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+	APISecret    string                   `json:"api_secret,omitempty"`
+	APIURL       string                   `json:"api_url,omitempty"`
+	CorpID       string                   `json:"corp_id,omitempty"`
+	Message      string                   `json:"message,omitempty"`
+	MessageType  string                   `json:"message_type,omitempty"`
+	AgentID      string                   `json:"agent_id,omitempty"`
+	ToUser       string                   `json:"to_user,omitempty"`
+	ToParty      string                   `json:"to_party,omitempty"`
+	ToTag        string                   `json:"to_tag,omitempty"`
+}
+
+type ReceiverSlackYAML struct {
+	SendResolved bool                     `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+	APIURL       string                   `json:"api_url,omitempty"`
+	APIURLFile   string                   `json:"api_url_file,omitempty"`
+
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+	Color    string `json:"color,omitempty"`
+
+	Title       string            `json:"title,omitempty"`
+	TitleLink   string            `json:"title_link,omitempty"`
+	Pretext     string            `json:"pretext,omitempty"`
+	Text        string            `json:"text,omitempty"`
+	Fields      []SlackFieldYAML  `json:"fields,omitempty"`
+	ShortFields bool              `json:"short_fields,omitempty"`
+	Footer      string            `json:"footer,omitempty"`
+	Fallback    string            `json:"fallback,omitempty"`
+	CallbackID  string            `json:"callback_id,omitempty"`
+	IconEmoji   string            `json:"icon_emoji,omitempty"`
+	IconURL     string            `json:"icon_url,omitempty"`
+	ImageURL    string            `json:"image_url,omitempty"`
+	ThumbURL    string            `json:"thumb_url,omitempty"`
+	LinkNames   bool              `json:"link_names,omitempty"`
+	MrkdwnIn    []string          `json:"mrkdwn_in,omitempty"`
+	Actions     []SlackActionYAML `json:"actions,omitempty"`
+}
+
+type SlackFieldYAML struct {
+	Title string `json:"title,omitempty"`
+	Value string `json:"value,omitempty"`
+	Short *bool  `json:"short,omitempty"`
+}
+
+type SlackActionYAML struct {
+	Type    string                      `json:"type,omitempty"`
+	Text    string                      `json:"text,omitempty"`
+	URL     string                      `json:"url,omitempty"`
+	Style   string                      `json:"style,omitempty"`
+	Name    string                      `json:"name,omitempty"`
+	Value   string                      `json:"value,omitempty"`
+	Confirm *SlackConfirmationFieldYAML `json:"confirm,omitempty"`
+}
+
+type SlackConfirmationFieldYAML struct {
+	Text        string `json:"text,omitempty"`
+	Title       string `json:"title,omitempty"`
+	OkText      string `json:"ok_text,omitempty"`
+	DismissText string `json:"dismiss_text,omitempty"`
+}
+
+type ReceiverPagerDutyYAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	// ServiceKey is used with the Events API v1, RoutingKey with v2.
+	ServiceKey     string `json:"service_key,omitempty"`
+	ServiceKeyFile string `json:"service_key_file,omitempty"`
+	RoutingKey     string `json:"routing_key,omitempty"`
+	RoutingKeyFile string `json:"routing_key_file,omitempty"`
+
+	URL         string               `json:"url,omitempty"`
+	Client      string               `json:"client,omitempty"`
+	ClientURL   string               `json:"client_url,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Details     map[string]string    `json:"details,omitempty"`
+	Images      []PagerDutyImageYAML `json:"images,omitempty"`
+	Links       []PagerDutyLinkYAML  `json:"links,omitempty"`
+	Source      string               `json:"source,omitempty"`
+	Severity    string               `json:"severity,omitempty"`
+	Class       string               `json:"class,omitempty"`
+	Component   string               `json:"component,omitempty"`
+	Group       string               `json:"group,omitempty"`
+}
+
+type PagerDutyImageYAML struct {
+	Src  string `json:"src,omitempty"`
+	Alt  string `json:"alt,omitempty"`
+	Href string `json:"href,omitempty"`
+}
+
+type PagerDutyLinkYAML struct {
+	Href string `json:"href,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+type ReceiverOpsGenieYAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	APIKey       string                  `json:"api_key,omitempty"`
+	APIKeyFile   string                  `json:"api_key_file,omitempty"`
+	APIURL       string                  `json:"api_url,omitempty"`
+	Message      string                  `json:"message,omitempty"`
+	Description  string                  `json:"description,omitempty"`
+	Source       string                  `json:"source,omitempty"`
+	Details      map[string]string       `json:"details,omitempty"`
+	Entity       string                  `json:"entity,omitempty"`
+	Responders   []OpsGenieResponderYAML `json:"responders,omitempty"`
+	Actions      string                  `json:"actions,omitempty"`
+	Tags         string                  `json:"tags,omitempty"`
+	Note         string                  `json:"note,omitempty"`
+	Priority     string                  `json:"priority,omitempty"`
+	UpdateAlerts bool                    `json:"update_alerts,omitempty"`
+}
+
+type OpsGenieResponderYAML struct {
+	// One of ID, Name or Username is required.
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Type is one of team, teams, user, escalation or schedule.
+	Type string `json:"type,omitempty"`
+}
+
+type ReceiverVictorOpsYAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	APIKey            string            `json:"api_key,omitempty"`
+	APIKeyFile        string            `json:"api_key_file,omitempty"`
+	APIURL            string            `json:"api_url,omitempty"`
+	RoutingKey        string            `json:"routing_key"`
+	MessageType       string            `json:"message_type,omitempty"`
+	StateMessage      string            `json:"state_message,omitempty"`
+	EntityDisplayName string            `json:"entity_display_name,omitempty"`
+	MonitoringTool    string            `json:"monitoring_tool,omitempty"`
+	CustomFields      map[string]string `json:"custom_fields,omitempty"`
+}
+
+type ReceiverTelegramYAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	APIURL               string `json:"api_url,omitempty"`
+	BotToken             string `json:"bot_token,omitempty"`
+	BotTokenFile         string `json:"bot_token_file,omitempty"`
+	ChatID               int64  `json:"chat_id,omitempty"`
+	Message              string `json:"message,omitempty"`
+	DisableNotifications bool   `json:"disable_notifications,omitempty"`
+	ParseMode            string `json:"parse_mode,omitempty"`
+}
+
+type ReceiverWebexYAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	APIURL  string `json:"api_url,omitempty"`
+	Message string `json:"message,omitempty"`
+	RoomID  string `json:"room_id"`
+}
+
+type ReceiverDiscordYAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	WebhookURL     string `json:"webhook_url,omitempty"`
+	WebhookURLFile string `json:"webhook_url_file,omitempty"`
+	Title          string `json:"title,omitempty"`
+	Message        string `json:"message,omitempty"`
+}
+
+type ReceiverMSTeamsYAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	WebhookURL     string `json:"webhook_url,omitempty"`
+	WebhookURLFile string `json:"webhook_url_file,omitempty"`
+	Title          string `json:"title,omitempty"`
+	Summary        string `json:"summary,omitempty"`
+	Text           string `json:"text,omitempty"`
+}
+
+type ReceiverPushoverYAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	UserKey     string         `json:"user_key,omitempty"`
+	UserKeyFile string         `json:"user_key_file,omitempty"`
+	Token       string         `json:"token,omitempty"`
+	TokenFile   string         `json:"token_file,omitempty"`
+	Title       string         `json:"title,omitempty"`
+	Message     string         `json:"message,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	URLTitle    string         `json:"url_title,omitempty"`
+	Device      string         `json:"device,omitempty"`
+	Sound       string         `json:"sound,omitempty"`
+	Priority    string         `json:"priority,omitempty"`
+	Retry       model.Duration `json:"retry,omitempty"`
+	Expire      model.Duration `json:"expire,omitempty"`
+	TTL         model.Duration `json:"ttl,omitempty"`
+	HTML        bool           `json:"html,omitempty"`
+}
+
+type ReceiverSNSYAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	APIURL      string            `json:"api_url,omitempty"`
+	SigV4       *SigV4YAML        `json:"sigv4,omitempty"`
+	TopicARN    string            `json:"topic_arn,omitempty"`
+	PhoneNumber string            `json:"phone_number,omitempty"`
+	TargetARN   string            `json:"target_arn,omitempty"`
+	Subject     string            `json:"subject,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+type ReceiverJiraYAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	APIURL      string   `json:"api_url,omitempty"`
+	Project     string   `json:"project"`
+	IssueType   string   `json:"issue_type"`
+	Summary     string   `json:"summary,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Priority    string   `json:"priority,omitempty"`
+
+	ReopenTransition  string         `json:"reopen_transition,omitempty"`
+	ResolveTransition string         `json:"resolve_transition,omitempty"`
+	WontFixResolution string         `json:"wont_fix_resolution,omitempty"`
+	ReopenDuration    model.Duration `json:"reopen_duration,omitempty"`
+
+	// Fields are custom issue fields by field ID, e.g. customfield_10000.
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+type ReceiverRocketchatYAML struct {
+	SendResolved bool                     `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	APIURL      string `json:"api_url,omitempty"`
+	TokenID     string `json:"token_id,omitempty"`
+	TokenIDFile string `json:"token_id_file,omitempty"`
+	Token       string `json:"token,omitempty"`
+	TokenFile   string `json:"token_file,omitempty"`
+
+	Channel     string                 `json:"channel,omitempty"`
+	Color       string                 `json:"color,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	TitleLink   string                 `json:"title_link,omitempty"`
+	Text        string                 `json:"text,omitempty"`
+	Fields      []RocketchatFieldYAML  `json:"fields,omitempty"`
+	ShortFields bool                   `json:"short_fields,omitempty"`
+	Emoji       string                 `json:"emoji,omitempty"`
+	IconURL     string                 `json:"icon_url,omitempty"`
+	ImageURL    string                 `json:"image_url,omitempty"`
+	ThumbURL    string                 `json:"thumb_url,omitempty"`
+	LinkNames   bool                   `json:"link_names,omitempty"`
+	Actions     []RocketchatActionYAML `json:"actions,omitempty"`
+}
+
+type RocketchatFieldYAML struct {
+	Short *bool  `json:"short,omitempty"`
+	Title string `json:"title,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+type RocketchatActionYAML struct {
+	Type string `json:"type,omitempty"`
+	Text string `json:"text,omitempty"`
+	URL  string `json:"url,omitempty"`
+	Msg  string `json:"msg,omitempty"`
+}
+
+// ReceiverMSTeamsV2YAML posts to Microsoft Teams through Power Automate
+// workflows, which replace the Office 365 connectors of msteams_configs.
+type ReceiverMSTeamsV2YAML struct {
+	SendResolved *bool                    `json:"send_resolved,omitempty"`
+	HTTPConfig   *config.HTTPClientConfig `json:"http_config,omitempty"`
+
+	WebhookURL     string `json:"webhook_url,omitempty"`
+	WebhookURLFile string `json:"webhook_url_file,omitempty"`
+	Title          string `json:"title,omitempty"`
+	Text           string `json:"text,omitempty"`
+}
+
+// SigV4YAML configures AWS Signature Version 4 request signing.
+type SigV4YAML struct {
+	Region    string `json:"region,omitempty"`
+	AccessKey string `json:"access_key,omitempty"`
+	SecretKey string `json:"secret_key,omitempty"`
+	Profile   string `json:"profile,omitempty"`
+	RoleARN   string `json:"role_arn,omitempty"`
 }
 
 type AlertManagerAPI interface {
 	ConfigYAML() AlertManagerYAML
+	// SetConfigYAML replaces the whole Alertmanager configuration, see UpdateConfigYAML.
+	SetConfigYAML(ctx context.Context, yml *AlertManagerYAML) error
+	// UpdateConfigYAML applies fn to a copy of the loaded configuration, writes the
+	// result to AlertManagerConfig.ConfigYAML (keeping a ".bak" backup) and reloads
+	// Alertmanager. The file is rolled back if the reload fails. Only the changes
+	// are written, keys the model does not know about and comments are kept.
+	UpdateConfigYAML(ctx context.Context, fn func(yml *AlertManagerYAML) error) error
+
+	GetReceiver(name string) (*AlertManagerReceiverYAML, error)
+	AddReceiver(ctx context.Context, receiver *AlertManagerReceiverYAML) error
+	UpdateReceiver(ctx context.Context, receiver *AlertManagerReceiverYAML) error
+	DeleteReceiver(ctx context.Context, name string) error
+
+	// Routes returns the route tree flattened in depth first order.
+	Routes() []FlatRoute
+	GetRoute(path RoutePath) (*AlertManagerRoute, error)
+	// FindRoutes returns the paths of the routes whose matchers (match, match_re
+	// and matchers combined) are exactly the given set.
+	FindRoutes(matchers ...Matcher) ([]RoutePath, error)
+	// InsertRoute inserts route as the pos-th child of the route at parent.
+	// A negative or out of range pos appends it.
+	InsertRoute(ctx context.Context, parent RoutePath, pos int, route *AlertManagerRoute) error
+	UpdateRoute(ctx context.Context, path RoutePath, route *AlertManagerRoute) error
+	// MoveRoute moves the route at from to the pos-th child of the route at parent.
+	// Both paths and pos refer to the tree before the move, so within the same
+	// parent the route lands before the child that was at pos. A negative or
+	// out of range pos appends it.
+	MoveRoute(ctx context.Context, from, parent RoutePath, pos int) error
+	RemoveRoute(ctx context.Context, path RoutePath) error
+
+	ListInhibitRules() []AlertManagerInhibitRuleYAML
+	AddInhibitRule(ctx context.Context, rule *AlertManagerInhibitRuleYAML) error
+	// RemoveInhibitRule removes the inhibit rule at index i of ListInhibitRules.
+	RemoveInhibitRule(ctx context.Context, i int) error
+
+	// GetTimeInterval looks up both time_intervals and mute_time_intervals.
+	GetTimeInterval(name string) (*AlertManagerTimeIntervalYAML, error)
+	AddTimeInterval(ctx context.Context, interval *AlertManagerTimeIntervalYAML) error
+	UpdateTimeInterval(ctx context.Context, interval *AlertManagerTimeIntervalYAML) error
+	// DeleteTimeInterval refuses to remove an interval still referenced by a route.
+	DeleteTimeInterval(ctx context.Context, name string) error
 
 	Healthy(ctx context.Context) error
 	Ready(ctx context.Context) error
 	Reload(ctx context.Context) error
+	// ReloadAndVerify reloads Alertmanager and waits up to timeout (30s when
+	// zero) until its metrics report a successful reload after this one was
+	// triggered, or a changed configuration hash. It returns a
+	// *ReloadNotAppliedError when that does not happen.
+	ReloadAndVerify(ctx context.Context, timeout time.Duration) error
+
+	CreateSilence(ctx context.Context, silence *Silence) (string, error)
+	ListSilences(ctx context.Context, matchers ...Matcher) ([]Silence, error)
+	GetSilence(ctx context.Context, id string) (*Silence, error)
+	UpdateSilence(ctx context.Context, silence *Silence) (string, error)
+	ExpireSilence(ctx context.Context, id string) error
+
+	ListAlerts(ctx context.Context, filter *AlertFilter) ([]AlertManagerAlert, error)
+	ListAlertGroups(ctx context.Context, filter *AlertFilter) ([]AlertManagerAlertGroup, error)
+	PostAlerts(ctx context.Context, alerts ...AlertManagerAlert) error
 }
 
 func NewAlertManagerAPI(hc *http.Client, cfg *AlertManagerConfig) (AlertManagerAPI, error) {
@@ -177,7 +570,10 @@ type alertManagerAPI struct {
 
 	hc *http.Client
 
+	mu  sync.RWMutex
 	yml *AlertManagerYAML
+	// raw is the content of the config file yml was loaded from.
+	raw []byte
 }
 
 func (api *alertManagerAPI) load() error {
@@ -192,11 +588,15 @@ func (api *alertManagerAPI) load() error {
 		return err
 	}
 	api.yml = &yml
+	api.raw = data
 
 	return nil
 }
 
 func (api *alertManagerAPI) ConfigYAML() AlertManagerYAML {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+
 	var out AlertManagerYAML
 	out = *api.yml
 	if api.yml.Global != nil {
@@ -206,6 +606,71 @@ func (api *alertManagerAPI) ConfigYAML() AlertManagerYAML {
 	return out
 }
 
+func (api *alertManagerAPI) SetConfigYAML(ctx context.Context, yml *AlertManagerYAML) error {
+	return api.UpdateConfigYAML(ctx, func(dst *AlertManagerYAML) error {
+		*dst = *yml
+		return nil
+	})
+}
+
+func (api *alertManagerAPI) UpdateConfigYAML(ctx context.Context, fn func(yml *AlertManagerYAML) error) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	yml, err := api.yml.clone()
+	if err != nil {
+		return err
+	}
+	if err = fn(yml); err != nil {
+		return err
+	}
+
+	return api.save(ctx, yml)
+}
+
+// save writes yml to the config file and reloads Alertmanager, restoring the
+// previous file if the reload fails. The caller must hold api.mu.
+func (api *alertManagerAPI) save(ctx context.Context, yml *AlertManagerYAML) error {
+	if err := yml.Validate(); err != nil {
+		return err
+	}
+
+	// merge into the original file to keep what AlertManagerYAML does not model
+	data, err := mergeYAML(api.raw, api.yml, yml)
+	if err != nil {
+		return err
+	}
+
+	restore, err := replaceFile(api.cfg.ConfigYAML, data)
+	if err != nil {
+		return err
+	}
+
+	if err = api.Reload(ctx); err != nil {
+		if rerr := restore(); rerr != nil {
+			return fmt.Errorf("reload alertmanager: %w (restore %s: %v)", err, api.cfg.ConfigYAML, rerr)
+		}
+		return fmt.Errorf("reload alertmanager: %w", err)
+	}
+	api.yml = yml
+	api.raw = data
+
+	return nil
+}
+
+func (yml *AlertManagerYAML) clone() (*AlertManagerYAML, error) {
+	data, err := marshalYAML(yml)
+	if err != nil {
+		return nil, err
+	}
+
+	var out AlertManagerYAML
+	if err = yaml.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (api *alertManagerAPI) endpoint() *urlpkg.URL {
 	endpoint := api.cfg.Endpoint
 	if !strings.HasPrefix(endpoint, "http") {
@@ -267,44 +732,150 @@ func (api *alertManagerAPI) Do(ctx context.Context, req *http.Request) (*http.Re
 	return resp, body, err
 }
 
-func (api *alertManagerAPI) Healthy(ctx context.Context) error {
-	url := api.URL("/-/healthy", map[string]string{})
-
+// doJSON sends in as the JSON request body (if not nil) and decodes a successful
+// response into out (if not nil).
+func (api *alertManagerAPI) doJSON(ctx context.Context, method string, url *urlpkg.URL, in, out any) error {
 	req := &http.Request{
-		Method: http.MethodGet,
+		Method: method,
 		URL:    url,
+		Header: http.Header{},
 	}
-	_, _, err := api.Do(ctx, req)
+	req.Header.Set("Accept", "application/json")
+
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, body, err := api.Do(ctx, req)
 	if err != nil {
 		return err
 	}
-	return err
-}
+	if err = responseError("alertmanager", req, resp, body, nil); err != nil {
+		return err
+	}
 
-func (api *alertManagerAPI) Ready(ctx context.Context) error {
-	url := api.URL("/-/ready", map[string]string{})
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
 
+// doLifecycle sends a request to a management endpoint of Alertmanager and
+// checks the status of the response, see responseError.
+func (api *alertManagerAPI) doLifecycle(ctx context.Context, method, path string, typed func(StatusError) error) error {
 	req := &http.Request{
-		Method: http.MethodGet,
-		URL:    url,
+		Method: method,
+		URL:    api.URL(path, map[string]string{}),
 	}
-	_, _, err := api.Do(ctx, req)
+	resp, body, err := api.Do(ctx, req)
 	if err != nil {
 		return err
 	}
-	return err
+	return responseError("alertmanager", req, resp, body, typed)
+}
+
+func (api *alertManagerAPI) Healthy(ctx context.Context) error {
+	return api.doLifecycle(ctx, http.MethodGet, "/-/healthy", func(e StatusError) error {
+		return &UnhealthyError{e}
+	})
+}
+
+func (api *alertManagerAPI) Ready(ctx context.Context) error {
+	return api.doLifecycle(ctx, http.MethodGet, "/-/ready", func(e StatusError) error {
+		return &NotReadyError{e}
+	})
 }
 
 func (api *alertManagerAPI) Reload(ctx context.Context) error {
-	url := api.URL("/-/reload", map[string]string{})
+	return api.doLifecycle(ctx, http.MethodPost, "/-/reload", func(e StatusError) error {
+		return &ReloadFailedError{e}
+	})
+}
+
+// alertManagerReloadState is what Alertmanager exposes in its metrics about the
+// last reload of its configuration.
+type alertManagerReloadState struct {
+	// alertmanager_config_last_reload_successful
+	successful bool
+	// alertmanager_config_hash
+	hash float64
+	// alertmanager_config_last_reload_success_timestamp_seconds
+	successTime float64
+}
 
+func (api *alertManagerAPI) reloadState(ctx context.Context) (*alertManagerReloadState, error) {
 	req := &http.Request{
-		Method: http.MethodPost,
-		URL:    url,
+		Method: http.MethodGet,
+		URL:    api.URL("/metrics", map[string]string{}),
+		Header: http.Header{},
 	}
-	_, _, err := api.Do(ctx, req)
+	req.Header.Set("Accept", "text/plain")
+	resp, body, err := api.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err = responseError("alertmanager", req, resp, body, nil); err != nil {
+		return nil, err
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("alertmanager metrics: %w", err)
+	}
+	values := map[string]float64{}
+	for _, name := range []string{
+		"alertmanager_config_last_reload_successful",
+		"alertmanager_config_hash",
+		"alertmanager_config_last_reload_success_timestamp_seconds",
+	} {
+		mf, ok := families[name]
+		if !ok || len(mf.GetMetric()) == 0 {
+			return nil, fmt.Errorf("alertmanager metrics: %s not found", name)
+		}
+		values[name] = mf.GetMetric()[0].GetGauge().GetValue()
+	}
+
+	return &alertManagerReloadState{
+		successful:  values["alertmanager_config_last_reload_successful"] == 1,
+		hash:        values["alertmanager_config_hash"],
+		successTime: values["alertmanager_config_last_reload_success_timestamp_seconds"],
+	}, nil
+}
+
+func (api *alertManagerAPI) ReloadAndVerify(ctx context.Context, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultReloadTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	before, err := api.reloadState(ctx)
 	if err != nil {
 		return err
 	}
-	return err
+
+	if err = api.Reload(ctx); err != nil {
+		return err
+	}
+
+	return verifyReload(ctx, "alertmanager", func(ctx context.Context) (bool, string, bool, error) {
+		state, err := api.reloadState(ctx)
+		if err != nil {
+			return false, "", false, err
+		}
+		if !state.successful {
+			return false, "last configuration reload was unsuccessful", true, nil
+		}
+		if state.successTime > before.successTime || state.hash != before.hash {
+			return true, "", false, nil
+		}
+		return false, "configuration hash and last successful reload time are unchanged", false, nil
+	})
 }
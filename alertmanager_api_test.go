@@ -1,11 +1,19 @@
 package pag
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
 )
 
 func getAlertmanagerAPI(t *testing.T) AlertManagerAPI {
@@ -21,6 +29,31 @@ func getAlertmanagerAPI(t *testing.T) AlertManagerAPI {
 	return api
 }
 
+// newAlertmanagerTestAPI returns an AlertManagerAPI backed by handler and a
+// temporary copy of testdata/alertmanager.yaml.
+func newAlertmanagerTestAPI(t *testing.T, handler http.Handler) AlertManagerAPI {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	data, err := os.ReadFile("testdata/alertmanager.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "alertmanager.yaml")
+	if err = os.WriteFile(dst, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	api, err := NewAlertManagerAPI(srv.Client(), &AlertManagerConfig{
+		Endpoint:   srv.URL,
+		ConfigYAML: dst,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
 func TestNewAlertManagerAPI(t *testing.T) {
 	api := getAlertmanagerAPI(t)
 
@@ -28,3 +61,143 @@ func TestNewAlertManagerAPI(t *testing.T) {
 
 	assert.Equal(t, cfg.Receivers[0].WebhookConfigs[0].URL, "http://127.0.0.1:8000/webhook")
 }
+
+func TestAlertManagerAPI_UpdateConfigYAML(t *testing.T) {
+	reloadStatus := http.StatusOK
+	mux := http.NewServeMux()
+	mux.HandleFunc("/-/reload", func(w http.ResponseWriter, r *http.Request) {
+		if reloadStatus != http.StatusOK {
+			http.Error(w, "failed to reload config", reloadStatus)
+		}
+	})
+
+	api := newAlertmanagerTestAPI(t, mux)
+	ctx := context.Background()
+	dst := api.(*alertManagerAPI).cfg.ConfigYAML
+	origin, _ := os.ReadFile(dst)
+
+	err := api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		yml.Route.Receiver = "updated"
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "updated", api.ConfigYAML().Route.Receiver)
+
+	backup, err := os.ReadFile(dst + ".bak")
+	assert.NoError(t, err)
+	assert.Equal(t, origin, backup)
+
+	var saved AlertManagerYAML
+	data, _ := os.ReadFile(dst)
+	assert.NoError(t, yaml.Unmarshal(data, &saved))
+	assert.Equal(t, "updated", saved.Route.Receiver)
+	assert.Len(t, saved.InhibitRule, 1)
+	assert.Equal(t, "http://127.0.0.1:8000/webhook", saved.Receivers[0].WebhookConfigs[0].URL)
+	// only the changed value is rewritten
+	assert.Contains(t, string(data), "  # ./*.tmpl\n")
+	assert.Contains(t, string(data), "  group_by: ['alertname']\n")
+
+	reloadStatus = http.StatusInternalServerError
+	err = api.SetConfigYAML(ctx, &AlertManagerYAML{})
+	assert.Error(t, err)
+	assert.Equal(t, "updated", api.ConfigYAML().Route.Receiver)
+
+	after, _ := os.ReadFile(dst)
+	assert.Equal(t, data, after)
+}
+
+func TestAlertManagerAPI_ReloadAndVerify(t *testing.T) {
+	reloadVerifyInterval = 10 * time.Millisecond
+	t.Cleanup(func() { reloadVerifyInterval = 500 * time.Millisecond })
+
+	var (
+		mu          sync.Mutex
+		successful  = 1
+		successTime = 1700000000.5
+		applies     = true
+	)
+	api := newAlertmanagerTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/-/reload":
+			if applies {
+				// the hash is unchanged when the file is, the timestamp is not
+				successTime += 0.25
+			}
+		case "/metrics":
+			fmt.Fprintf(w, `# TYPE alertmanager_config_hash gauge
+alertmanager_config_hash 2.1474836e+09
+# TYPE alertmanager_config_last_reload_success_timestamp_seconds gauge
+alertmanager_config_last_reload_success_timestamp_seconds %f
+# TYPE alertmanager_config_last_reload_successful gauge
+alertmanager_config_last_reload_successful %d
+`, successTime, successful)
+		}
+	}))
+	ctx := context.Background()
+
+	assert.NoError(t, api.ReloadAndVerify(ctx, time.Second))
+
+	// Alertmanager accepted the reload but kept running the old configuration
+	mu.Lock()
+	applies = false
+	mu.Unlock()
+	err := api.ReloadAndVerify(ctx, 50*time.Millisecond)
+	var notApplied *ReloadNotAppliedError
+	if assert.True(t, errors.As(err, &notApplied)) {
+		assert.Equal(t, "alertmanager", notApplied.Service)
+		assert.Contains(t, notApplied.Reason, "unchanged")
+	}
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// alertmanager_config_last_reload_successful is 0
+	mu.Lock()
+	successful = 0
+	mu.Unlock()
+	err = api.ReloadAndVerify(ctx, time.Second)
+	assert.EqualError(t, err, "alertmanager reload not applied: last configuration reload was unsuccessful")
+}
+
+func TestAlertManagerAPI_LifecycleErrors(t *testing.T) {
+	status := map[string]int{}
+	api := newAlertmanagerTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code, ok := status[r.URL.Path]; ok {
+			http.Error(w, "failed to reload config: yaml: line 3: did not find expected key", code)
+		}
+	}))
+	ctx := context.Background()
+
+	assert.NoError(t, api.Healthy(ctx))
+	assert.NoError(t, api.Ready(ctx))
+	assert.NoError(t, api.Reload(ctx))
+
+	status["/-/ready"] = http.StatusServiceUnavailable
+	var notReady *NotReadyError
+	assert.True(t, errors.As(api.Ready(ctx), &notReady))
+
+	status["/-/healthy"] = http.StatusServiceUnavailable
+	var unhealthy *UnhealthyError
+	assert.True(t, errors.As(api.Healthy(ctx), &unhealthy))
+
+	status["/-/reload"] = http.StatusInternalServerError
+	var reloadFailed *ReloadFailedError
+	if assert.True(t, errors.As(api.Reload(ctx), &reloadFailed)) {
+		assert.Equal(t, "alertmanager", reloadFailed.Service)
+		assert.Contains(t, reloadFailed.Message, "did not find expected key")
+	}
+
+	status["/api/v2/silences"] = http.StatusForbidden
+	_, err := api.ListSilences(ctx)
+	var unauthorized *UnauthorizedError
+	assert.True(t, errors.As(err, &unauthorized))
+
+	status["/api/v2/alerts"] = http.StatusBadRequest
+	_, err = api.ListAlerts(ctx, nil)
+	var statusErr *StatusError
+	if assert.True(t, errors.As(err, &statusErr)) {
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	}
+}
@@ -0,0 +1,117 @@
+package pag
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Inhibition reports an alert suppressed by an inhibit rule. Target, Rule and
+// Source are indexes into the alerts and rules given to InhibitedAlerts.
+type Inhibition struct {
+	Target int
+	Rule   int
+	Source int
+}
+
+// InhibitedAlerts reports which of the alerts, given as label sets, would be
+// inhibited by the rules. An alert is inhibited when it matches the target
+// matchers of a rule and another alert matches the source matchers with the
+// same values for the rule's equal labels. Like Alertmanager, an alert
+// matching both sides of a rule is not inhibited by alerts that also do.
+func InhibitedAlerts(rules []AlertManagerInhibitRuleYAML, alerts []map[string]string) ([]Inhibition, error) {
+	var out []Inhibition
+	for ri, rule := range rules {
+		sourceMatchers, err := rule.AllSourceMatchers()
+		if err != nil {
+			return nil, fmt.Errorf("inhibit rule %d: %w", ri, err)
+		}
+		targetMatchers, err := rule.AllTargetMatchers()
+		if err != nil {
+			return nil, fmt.Errorf("inhibit rule %d: %w", ri, err)
+		}
+
+		sources := make([]bool, len(alerts))
+		targets := make([]bool, len(alerts))
+		for i, labels := range alerts {
+			if sources[i], err = matchAll(sourceMatchers, labels); err != nil {
+				return nil, fmt.Errorf("inhibit rule %d: %w", ri, err)
+			}
+			if targets[i], err = matchAll(targetMatchers, labels); err != nil {
+				return nil, fmt.Errorf("inhibit rule %d: %w", ri, err)
+			}
+		}
+
+		for ti, target := range alerts {
+			if !targets[ti] {
+				continue
+			}
+			for si, source := range alerts {
+				if si == ti || !sources[si] {
+					continue
+				}
+				if sources[ti] && targets[si] {
+					continue
+				}
+				if equalLabels(rule.Equal, source, target) {
+					out = append(out, Inhibition{Target: ti, Rule: ri, Source: si})
+					break
+				}
+			}
+		}
+	}
+
+	return out, nil
+}
+
+func equalLabels(names []string, a, b map[string]string) bool {
+	for _, name := range names {
+		if a[name] != b[name] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *AlertManagerInhibitRuleYAML) AllSourceMatchers() ([]Matcher, error) {
+	return combineMatchers(r.SourceMatch, r.SourceMatchRe, r.SourceMatchers)
+}
+
+func (r *AlertManagerInhibitRuleYAML) AllTargetMatchers() ([]Matcher, error) {
+	return combineMatchers(r.TargetMatch, r.TargetMatchRe, r.TargetMatchers)
+}
+
+func (api *alertManagerAPI) ListInhibitRules() []AlertManagerInhibitRuleYAML {
+	yml := api.ConfigYAML()
+
+	return append([]AlertManagerInhibitRuleYAML(nil), yml.InhibitRule...)
+}
+
+func (api *alertManagerAPI) AddInhibitRule(ctx context.Context, rule *AlertManagerInhibitRuleYAML) error {
+	if _, err := rule.AllSourceMatchers(); err != nil {
+		return err
+	}
+	if _, err := rule.AllTargetMatchers(); err != nil {
+		return err
+	}
+
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		for _, item := range yml.InhibitRule {
+			if reflect.DeepEqual(item, *rule) {
+				return fmt.Errorf("inhibit rule: %w", ErrAlreadyExists)
+			}
+		}
+		yml.InhibitRule = append(yml.InhibitRule, *rule)
+		return nil
+	})
+}
+
+func (api *alertManagerAPI) RemoveInhibitRule(ctx context.Context, i int) error {
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		if i < 0 || i >= len(yml.InhibitRule) {
+			return fmt.Errorf("inhibit rule %d: %w", i, ErrNotFound)
+		}
+		yml.InhibitRule = append(yml.InhibitRule[:i], yml.InhibitRule[i+1:]...)
+		return nil
+	})
+}
@@ -0,0 +1,71 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInhibitedAlerts(t *testing.T) {
+	rules := []AlertManagerInhibitRuleYAML{
+		{
+			SourceMatch: map[string]string{"severity": "critical"},
+			TargetMatch: map[string]string{"severity": "warning"},
+			Equal:       []string{"alertname", "instance"},
+		},
+		{
+			SourceMatchers: []string{`alertname="ClusterDown"`},
+			TargetMatchers: []string{`alertname=~".+"`},
+			Equal:          []string{"cluster"},
+		},
+	}
+	alerts := []map[string]string{
+		{"alertname": "HighLoad", "instance": "a", "severity": "critical", "cluster": "c1"},
+		{"alertname": "HighLoad", "instance": "a", "severity": "warning", "cluster": "c1"},
+		{"alertname": "HighLoad", "instance": "b", "severity": "warning", "cluster": "c2"},
+		{"alertname": "ClusterDown", "cluster": "c2"},
+	}
+
+	inhibitions, err := InhibitedAlerts(rules, alerts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Inhibition{
+		{Target: 1, Rule: 0, Source: 0},
+		{Target: 2, Rule: 1, Source: 3},
+	}, inhibitions)
+
+	rules[1].SourceMatchers = []string{`alertname`}
+	_, err = InhibitedAlerts(rules, alerts)
+	assert.Error(t, err)
+}
+
+func TestAlertManagerAPI_InhibitRules(t *testing.T) {
+	api := newAlertmanagerTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ctx := context.Background()
+
+	rules := api.ListInhibitRules()
+	if !assert.Len(t, rules, 1) {
+		return
+	}
+	assert.Equal(t, []string{"alertname", "dev", "instance"}, rules[0].Equal)
+
+	rule := &AlertManagerInhibitRuleYAML{
+		SourceMatchers: []string{`alertname="ClusterDown"`},
+		TargetMatchers: []string{`severity="warning"`},
+		Equal:          []string{"cluster"},
+	}
+	assert.NoError(t, api.AddInhibitRule(ctx, rule))
+	assert.True(t, errors.Is(api.AddInhibitRule(ctx, rule), ErrAlreadyExists))
+	assert.Error(t, api.AddInhibitRule(ctx, &AlertManagerInhibitRuleYAML{SourceMatchers: []string{"bad"}}))
+	assert.Len(t, api.ListInhibitRules(), 2)
+
+	assert.NoError(t, api.RemoveInhibitRule(ctx, 0))
+	rules = api.ListInhibitRules()
+	assert.Len(t, rules, 1)
+	assert.Equal(t, []string{"cluster"}, rules[0].Equal)
+	assert.True(t, errors.Is(api.RemoveInhibitRule(ctx, 1), ErrNotFound))
+}
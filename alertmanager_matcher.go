@@ -0,0 +1,165 @@
+package pag
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matcher matches an alert label, as used by silences, alert filters and routes.
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	// IsEqual defaults to true when omitted by the server.
+	IsEqual *bool `json:"isEqual,omitempty"`
+}
+
+// String returns the matcher in the Alertmanager filter notation, e.g. `job=~"node.*"`.
+func (m Matcher) String() string {
+	return m.Name + m.op() + strconv.Quote(m.Value)
+}
+
+func (m Matcher) op() string {
+	equal := m.IsEqual == nil || *m.IsEqual
+
+	switch {
+	case equal && m.IsRegex:
+		return "=~"
+	case equal:
+		return "="
+	case m.IsRegex:
+		return "!~"
+	default:
+		return "!="
+	}
+}
+
+// Matches reports whether the label set satisfies the matcher. A missing label
+// matches as the empty string, and regular expressions are fully anchored.
+func (m Matcher) Matches(labels map[string]string) (bool, error) {
+	equal := m.IsEqual == nil || *m.IsEqual
+	value := labels[m.Name]
+
+	if !m.IsRegex {
+		return (value == m.Value) == equal, nil
+	}
+
+	re, err := regexp.Compile("^(?:" + m.Value + ")$")
+	if err != nil {
+		return false, fmt.Errorf("matcher %s: %w", m, err)
+	}
+	return re.MatchString(value) == equal, nil
+}
+
+// combineMatchers merges the deprecated match and match_re maps with the
+// matchers list into a single list of matchers.
+func combineMatchers(match, matchRe map[string]string, matchers []string) ([]Matcher, error) {
+	var out []Matcher
+	for name, value := range match {
+		out = append(out, Matcher{Name: name, Value: value})
+	}
+	for name, value := range matchRe {
+		out = append(out, Matcher{Name: name, Value: value, IsRegex: true})
+	}
+	for _, s := range matchers {
+		ms, err := ParseMatchers(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ms...)
+	}
+	return out, nil
+}
+
+func matchAll(matchers []Matcher, labels map[string]string) (bool, error) {
+	for _, m := range matchers {
+		ok, err := m.Matches(labels)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// ParseMatcher parses a single matcher such as `job="node"`, `job=~node.*`
+// or `severity!="info"`. The value may be quoted or not.
+func ParseMatcher(s string) (Matcher, error) {
+	s = strings.TrimSpace(s)
+
+	i := strings.IndexAny(s, "=!~")
+	if i <= 0 {
+		return Matcher{}, fmt.Errorf("bad matcher %q: missing label name or operator", s)
+	}
+
+	m := Matcher{Name: strings.TrimSpace(s[:i])}
+	rest := s[i:]
+	for _, op := range []string{"=~", "!~", "!=", "="} {
+		if !strings.HasPrefix(rest, op) {
+			continue
+		}
+		m.IsRegex = strings.HasSuffix(op, "~")
+		if strings.HasPrefix(op, "!") {
+			equal := false
+			m.IsEqual = &equal
+		}
+		rest = strings.TrimSpace(rest[len(op):])
+
+		if strings.HasPrefix(rest, `"`) {
+			value, err := strconv.Unquote(rest)
+			if err != nil {
+				return Matcher{}, fmt.Errorf("bad matcher %q: %w", s, err)
+			}
+			m.Value = value
+		} else {
+			m.Value = rest
+		}
+		return m, nil
+	}
+
+	return Matcher{}, fmt.Errorf("bad matcher %q: unknown operator", s)
+}
+
+// ParseMatchers parses a comma separated list of matchers, optionally
+// enclosed in braces, e.g. `{job="node", severity=~"warning|critical"}`.
+func ParseMatchers(s string) ([]Matcher, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+
+	var out []Matcher
+	var quoted, escaped bool
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) {
+			switch c := s[i]; {
+			case escaped:
+				escaped = false
+				continue
+			case c == '\\' && quoted:
+				escaped = true
+				continue
+			case c == '"':
+				quoted = !quoted
+				continue
+			case c != ',' || quoted:
+				continue
+			}
+		}
+
+		item := strings.TrimSpace(s[start:i])
+		start = i + 1
+		if item == "" {
+			continue
+		}
+		m, err := ParseMatcher(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,36 @@
+package pag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher_String(t *testing.T) {
+	notEqual := false
+
+	assert.Equal(t, `job="node"`, Matcher{Name: "job", Value: "node"}.String())
+	assert.Equal(t, `job=~"node.*"`, Matcher{Name: "job", Value: "node.*", IsRegex: true}.String())
+	assert.Equal(t, `job!="node"`, Matcher{Name: "job", Value: "node", IsEqual: &notEqual}.String())
+	assert.Equal(t, `job!~"node.*"`, Matcher{Name: "job", Value: "node.*", IsRegex: true, IsEqual: &notEqual}.String())
+}
+
+func TestParseMatchers(t *testing.T) {
+	matchers, err := ParseMatchers(`{job="node", severity=~"warning|critical", env!=prod, summary!~"a,\"b\""}`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out []string
+	for _, m := range matchers {
+		out = append(out, m.String())
+	}
+	assert.Equal(t, []string{`job="node"`, `severity=~"warning|critical"`, `env!="prod"`, `summary!~"a,\"b\""`}, out)
+
+	_, err = ParseMatcher(`="node"`)
+	assert.Error(t, err)
+	_, err = ParseMatcher(`job~"node"`)
+	assert.Error(t, err)
+	_, err = ParseMatcher(`job="node`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+func (yml *AlertManagerYAML) receiverIndex(name string) int {
+	for i, receiver := range yml.Receivers {
+		if receiver.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (api *alertManagerAPI) GetReceiver(name string) (*AlertManagerReceiverYAML, error) {
+	yml := api.ConfigYAML()
+
+	i := yml.receiverIndex(name)
+	if i < 0 {
+		return nil, fmt.Errorf("receiver %q: %w", name, ErrNotFound)
+	}
+	out := yml.Receivers[i]
+	return &out, nil
+}
+
+func (api *alertManagerAPI) AddReceiver(ctx context.Context, receiver *AlertManagerReceiverYAML) error {
+	if receiver.Name == "" {
+		return errors.New("receiver name is required")
+	}
+
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		if yml.receiverIndex(receiver.Name) >= 0 {
+			return fmt.Errorf("receiver %q: %w", receiver.Name, ErrAlreadyExists)
+		}
+		yml.Receivers = append(yml.Receivers, *receiver)
+		return nil
+	})
+}
+
+func (api *alertManagerAPI) UpdateReceiver(ctx context.Context, receiver *AlertManagerReceiverYAML) error {
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		i := yml.receiverIndex(receiver.Name)
+		if i < 0 {
+			return fmt.Errorf("receiver %q: %w", receiver.Name, ErrNotFound)
+		}
+		yml.Receivers[i] = *receiver
+		return nil
+	})
+}
+
+// DeleteReceiver removes the named receiver. It refuses to remove a receiver
+// that is still referenced by a route.
+func (api *alertManagerAPI) DeleteReceiver(ctx context.Context, name string) error {
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		i := yml.receiverIndex(name)
+		if i < 0 {
+			return fmt.Errorf("receiver %q: %w", name, ErrNotFound)
+		}
+
+		var used bool
+		yml.Route.walk(func(route *AlertManagerRoute) {
+			used = used || route.Receiver == name
+		})
+		if used {
+			return fmt.Errorf("receiver %q is still referenced by a route", name)
+		}
+
+		yml.Receivers = append(yml.Receivers[:i], yml.Receivers[i+1:]...)
+		return nil
+	})
+}
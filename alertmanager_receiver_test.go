@@ -0,0 +1,163 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestAlertManagerAPI_Receivers(t *testing.T) {
+	var reloads int
+	api := newAlertmanagerTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/-/reload" {
+			reloads++
+		}
+	}))
+	ctx := context.Background()
+
+	receiver := &AlertManagerReceiverYAML{
+		Name: "tenant-a",
+		WebhookConfigs: []ReceiverWebhookYAML{
+			{URL: "http://tenant-a.example.com/webhook"},
+		},
+	}
+	assert.NoError(t, api.AddReceiver(ctx, receiver))
+	assert.True(t, errors.Is(api.AddReceiver(ctx, receiver), ErrAlreadyExists))
+	assert.Error(t, api.AddReceiver(ctx, &AlertManagerReceiverYAML{}))
+
+	got, err := api.GetReceiver("tenant-a")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "http://tenant-a.example.com/webhook", got.WebhookConfigs[0].URL)
+
+	receiver.WebhookConfigs[0].URL = "http://tenant-a.example.com/v2/webhook"
+	assert.NoError(t, api.UpdateReceiver(ctx, receiver))
+	got, _ = api.GetReceiver("tenant-a")
+	assert.Equal(t, "http://tenant-a.example.com/v2/webhook", got.WebhookConfigs[0].URL)
+	assert.True(t, errors.Is(api.UpdateReceiver(ctx, &AlertManagerReceiverYAML{Name: "missing"}), ErrNotFound))
+
+	// web.hook is the receiver of the root route
+	assert.Error(t, api.DeleteReceiver(ctx, "web.hook"))
+
+	assert.NoError(t, api.DeleteReceiver(ctx, "tenant-a"))
+	_, err = api.GetReceiver("tenant-a")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(api.DeleteReceiver(ctx, "tenant-a"), ErrNotFound))
+
+	assert.Equal(t, 3, reloads)
+}
+
+func TestAlertManagerAPI_ReceiverSendResolved(t *testing.T) {
+	api := newAlertmanagerTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ctx := context.Background()
+
+	sendResolved := false
+	err := api.AddReceiver(ctx, &AlertManagerReceiverYAML{
+		Name: "pager",
+		PagerDutyConfigs: []ReceiverPagerDutyYAML{
+			{RoutingKey: "k", SendResolved: &sendResolved},
+			{RoutingKey: "default"},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	data, err := os.ReadFile(api.(*alertManagerAPI).cfg.ConfigYAML)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(data), "send_resolved: false")
+	assert.Equal(t, 1, strings.Count(string(data), "send_resolved"))
+
+	got, err := api.GetReceiver("pager")
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.NotNil(t, got.PagerDutyConfigs[0].SendResolved) {
+		assert.False(t, *got.PagerDutyConfigs[0].SendResolved)
+	}
+	// unset keeps the Alertmanager default, true for PagerDuty
+	assert.Nil(t, got.PagerDutyConfigs[1].SendResolved)
+}
+
+func TestAlertManagerReceiverYAML_Integrations(t *testing.T) {
+	data := []byte(`
+global:
+  slack_api_url: https://hooks.slack.com/services/T0/B0/X
+  pagerduty_url: https://events.pagerduty.com/v2/enqueue
+  telegram_api_url: https://api.telegram.org
+receivers:
+  - name: team
+    slack_configs:
+      - channel: '#alerts'
+        send_resolved: true
+        actions:
+          - type: button
+            text: Runbook
+            url: https://runbooks.example.com
+    pagerduty_configs:
+      - routing_key: secret-key
+        severity: critical
+        http_config:
+          proxy_url: http://proxy.example.com:3128
+    telegram_configs:
+      - bot_token: bot-token
+        chat_id: -1001234567890
+        parse_mode: HTML
+    opsgenie_configs:
+      - api_key: opsgenie-key
+        responders:
+          - name: sre
+            type: team
+    jira_configs:
+      - api_url: https://example.atlassian.net
+        project: OPS
+        issue_type: Bug
+        fields:
+          customfield_10000: team-a
+    rocketchat_configs:
+      - channel: '#alerts'
+        token_id: rocket-id
+        token: rocket-token
+    msteamsv2_configs:
+      - webhook_url: https://example.webhook.office.com/workflows/1
+        send_resolved: false
+`)
+
+	var yml AlertManagerYAML
+	if !assert.NoError(t, yaml.Unmarshal(data, &yml)) {
+		return
+	}
+	receiver := yml.Receivers[0]
+	assert.Equal(t, "https://hooks.slack.com/services/T0/B0/X", yml.Global.SlackAPIURL)
+	assert.Equal(t, "#alerts", receiver.SlackConfigs[0].Channel)
+	assert.Equal(t, "Runbook", receiver.SlackConfigs[0].Actions[0].Text)
+	assert.Equal(t, "secret-key", receiver.PagerDutyConfigs[0].RoutingKey)
+	assert.Equal(t, int64(-1001234567890), receiver.TelegramConfigs[0].ChatID)
+	assert.Equal(t, "team", receiver.OpsGenieConfigs[0].Responders[0].Type)
+	assert.Equal(t, "team-a", receiver.JiraConfigs[0].Fields["customfield_10000"])
+	assert.Equal(t, "rocket-id", receiver.RocketchatConfigs[0].TokenID)
+	if assert.NotNil(t, receiver.MSTeamsV2Configs[0].SendResolved) {
+		assert.False(t, *receiver.MSTeamsV2Configs[0].SendResolved)
+	}
+
+	out, err := marshalYAML(&yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var again AlertManagerYAML
+	if !assert.NoError(t, yaml.Unmarshal(out, &again)) {
+		return
+	}
+	assert.Equal(t, yml, again)
+	assert.Contains(t, string(out), "proxy_url: http://proxy.example.com:3128")
+}
@@ -0,0 +1,333 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RoutePath addresses a route in the tree by the child indexes leading to it
+// from the root route. An empty path is the root route.
+type RoutePath []int
+
+func (p RoutePath) String() string {
+	parts := make([]string, 0, len(p))
+	for _, i := range p {
+		parts = append(parts, strconv.Itoa(i))
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+func (p RoutePath) hasPrefix(prefix RoutePath) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if p[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// FlatRoute is a route of the tree with its position, used for display.
+// Route.Routes is always empty, children follow their parent in the list.
+type FlatRoute struct {
+	Path  RoutePath
+	Depth int
+	Route AlertManagerRoute
+}
+
+// walk calls fn for the route and all of its descendants, depth first.
+func (r *AlertManagerRoute) walk(fn func(route *AlertManagerRoute)) {
+	r.walkPath(nil, func(route *AlertManagerRoute, _ RoutePath) {
+		fn(route)
+	})
+}
+
+func (r *AlertManagerRoute) walkPath(path RoutePath, fn func(route *AlertManagerRoute, path RoutePath)) {
+	fn(r, path)
+	for i, child := range r.Routes {
+		if child != nil {
+			child.walkPath(append(path[:len(path):len(path)], i), fn)
+		}
+	}
+}
+
+// Get returns the descendant route at path.
+func (r *AlertManagerRoute) Get(path RoutePath) (*AlertManagerRoute, error) {
+	cur := r
+	for depth, i := range path {
+		if i < 0 || i >= len(cur.Routes) || cur.Routes[i] == nil {
+			return nil, fmt.Errorf("route %s: %w", path[:depth+1], ErrNotFound)
+		}
+		cur = cur.Routes[i]
+	}
+	return cur, nil
+}
+
+// Flatten returns the route tree in depth first order.
+func (r *AlertManagerRoute) Flatten() []FlatRoute {
+	var out []FlatRoute
+	r.walkPath(nil, func(route *AlertManagerRoute, path RoutePath) {
+		flat := FlatRoute{
+			Path:  path,
+			Depth: len(path),
+			Route: *route,
+		}
+		flat.Route.Routes = nil
+		out = append(out, flat)
+	})
+	return out
+}
+
+// AllMatchers returns the match, match_re and matchers of the route as a
+// single list of matchers.
+func (r *AlertManagerRoute) AllMatchers() ([]Matcher, error) {
+	return combineMatchers(r.Match, r.MatchRe, r.Matchers)
+}
+
+func matcherSet(matchers []Matcher) []string {
+	out := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		out = append(out, m.String())
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Find returns the paths of the routes whose matchers are exactly the given set.
+func (r *AlertManagerRoute) Find(matchers ...Matcher) ([]RoutePath, error) {
+	want := strings.Join(matcherSet(matchers), ",")
+
+	var out []RoutePath
+	var err error
+	r.walkPath(nil, func(route *AlertManagerRoute, path RoutePath) {
+		if err != nil {
+			return
+		}
+		var ms []Matcher
+		if ms, err = route.AllMatchers(); err != nil {
+			err = fmt.Errorf("route %s: %w", path, err)
+			return
+		}
+		if strings.Join(matcherSet(ms), ",") == want {
+			out = append(out, path)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *AlertManagerRoute) insert(pos int, route *AlertManagerRoute) {
+	if pos < 0 || pos > len(r.Routes) {
+		pos = len(r.Routes)
+	}
+	r.Routes = append(r.Routes, nil)
+	copy(r.Routes[pos+1:], r.Routes[pos:])
+	r.Routes[pos] = route
+}
+
+// detach removes the route at path from its parent and returns it.
+func (r *AlertManagerRoute) detach(path RoutePath) (*AlertManagerRoute, error) {
+	if len(path) == 0 {
+		return nil, errors.New("the root route cannot be removed")
+	}
+	parent, err := r.Get(path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	i := path[len(path)-1]
+	if i < 0 || i >= len(parent.Routes) {
+		return nil, fmt.Errorf("route %s: %w", path, ErrNotFound)
+	}
+
+	route := parent.Routes[i]
+	parent.Routes = append(parent.Routes[:i], parent.Routes[i+1:]...)
+	return route, nil
+}
+
+func (api *alertManagerAPI) Routes() []FlatRoute {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+
+	return api.yml.Route.Flatten()
+}
+
+func (api *alertManagerAPI) GetRoute(path RoutePath) (*AlertManagerRoute, error) {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+
+	yml, err := api.yml.clone()
+	if err != nil {
+		return nil, err
+	}
+	return yml.Route.Get(path)
+}
+
+func (api *alertManagerAPI) FindRoutes(matchers ...Matcher) ([]RoutePath, error) {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+
+	return api.yml.Route.Find(matchers...)
+}
+
+func (api *alertManagerAPI) InsertRoute(ctx context.Context, parent RoutePath, pos int, route *AlertManagerRoute) error {
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		p, err := yml.Route.Get(parent)
+		if err != nil {
+			return err
+		}
+		r := *route
+		p.insert(pos, &r)
+		return nil
+	})
+}
+
+func (api *alertManagerAPI) UpdateRoute(ctx context.Context, path RoutePath, route *AlertManagerRoute) error {
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		r, err := yml.Route.Get(path)
+		if err != nil {
+			return err
+		}
+		*r = *route
+		return nil
+	})
+}
+
+func (api *alertManagerAPI) MoveRoute(ctx context.Context, from, parent RoutePath, pos int) error {
+	if parent.hasPrefix(from) {
+		return fmt.Errorf("cannot move route %s into its own subtree %s", from, parent)
+	}
+
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		p, err := yml.Route.Get(parent)
+		if err != nil {
+			return err
+		}
+		// pos counts the children before the move, within the same parent the
+		// ones after the moved route shift down once it is detached
+		if len(from) == len(parent)+1 && from.hasPrefix(parent) && from[len(parent)] < pos && pos <= len(p.Routes) {
+			pos--
+		}
+		route, err := yml.Route.detach(from)
+		if err != nil {
+			return err
+		}
+		p.insert(pos, route)
+		return nil
+	})
+}
+
+func (api *alertManagerAPI) RemoveRoute(ctx context.Context, path RoutePath) error {
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		_, err := yml.Route.detach(path)
+		return err
+	})
+}
+
+// RouteMatch is a route an alert would be dispatched to.
+type RouteMatch struct {
+	Path RoutePath
+	// Receiver and GroupBy are inherited from the parent routes when not set.
+	Receiver string
+	GroupBy  []string
+	// GroupLabels are the labels of the alert the notification would be grouped by.
+	GroupLabels map[string]string
+}
+
+// MatchRoutes returns the routes an alert with the given labels would be
+// dispatched to, the same way Alertmanager (and `amtool config routes test`)
+// walks the route tree: the first matching child wins unless it has continue
+// set, and a route none of whose children match handles the alert itself.
+func (yml *AlertManagerYAML) MatchRoutes(labels map[string]string) ([]RouteMatch, error) {
+	return yml.Route.match(nil, labels, RouteMatch{})
+}
+
+// MatchReceivers returns the distinct receivers an alert with the given labels would be sent to.
+func (yml *AlertManagerYAML) MatchReceivers(labels map[string]string) ([]string, error) {
+	matches, err := yml.MatchRoutes(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	seen := map[string]bool{}
+	for _, match := range matches {
+		if !seen[match.Receiver] {
+			seen[match.Receiver] = true
+			out = append(out, match.Receiver)
+		}
+	}
+	return out, nil
+}
+
+func (r *AlertManagerRoute) match(path RoutePath, labels map[string]string, parent RouteMatch) ([]RouteMatch, error) {
+	matchers, err := r.AllMatchers()
+	if err != nil {
+		return nil, fmt.Errorf("route %s: %w", path, err)
+	}
+	ok, err := matchAll(matchers, labels)
+	if err != nil {
+		return nil, fmt.Errorf("route %s: %w", path, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	current := RouteMatch{
+		Path:     path,
+		Receiver: parent.Receiver,
+		GroupBy:  parent.GroupBy,
+	}
+	if r.Receiver != "" {
+		current.Receiver = r.Receiver
+	}
+	if r.GroupBy != nil {
+		current.GroupBy = r.GroupBy
+	}
+
+	var out []RouteMatch
+	for i, child := range r.Routes {
+		if child == nil {
+			continue
+		}
+		matches, err := child.match(append(path[:len(path):len(path)], i), labels, current)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, matches...)
+		if matches != nil && !child.Continue {
+			break
+		}
+	}
+
+	if len(out) == 0 {
+		current.GroupLabels = groupLabels(current.GroupBy, labels)
+		out = append(out, current)
+	}
+
+	return out, nil
+}
+
+func groupLabels(groupBy []string, labels map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, name := range groupBy {
+		// "..." groups by all labels
+		if name == "..." {
+			for k, v := range labels {
+				out[k] = v
+			}
+			return out
+		}
+		if v, ok := labels[name]; ok {
+			out[name] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertManagerRoute_Tree(t *testing.T) {
+	root := &AlertManagerRoute{
+		Receiver: "default",
+		Routes: []*AlertManagerRoute{
+			{
+				Receiver: "team-a",
+				Match:    map[string]string{"team": "a"},
+				Routes: []*AlertManagerRoute{
+					{Receiver: "team-a-pager", Matchers: []string{`severity="critical"`, `team="a"`}},
+				},
+			},
+			{Receiver: "team-b", MatchRe: map[string]string{"team": "b|c"}},
+		},
+	}
+
+	flat := root.Flatten()
+	if !assert.Len(t, flat, 4) {
+		return
+	}
+	assert.Equal(t, "/", flat[0].Path.String())
+	assert.Equal(t, "/0/0", flat[2].Path.String())
+	assert.Equal(t, 2, flat[2].Depth)
+	assert.Equal(t, "team-b", flat[3].Route.Receiver)
+	assert.Nil(t, flat[1].Route.Routes)
+
+	route, err := root.Get(RoutePath{0, 0})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "team-a-pager", route.Receiver)
+
+	_, err = root.Get(RoutePath{1, 0})
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	paths, err := root.Find(Matcher{Name: "team", Value: "a"}, Matcher{Name: "severity", Value: "critical"})
+	assert.NoError(t, err)
+	assert.Equal(t, []RoutePath{{0, 0}}, paths)
+
+	paths, err = root.Find(Matcher{Name: "team", Value: "b|c", IsRegex: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []RoutePath{{1}}, paths)
+}
+
+func TestAlertManagerAPI_Routes(t *testing.T) {
+	api := newAlertmanagerTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ctx := context.Background()
+
+	receivers := func() []string {
+		var out []string
+		for _, flat := range api.Routes() {
+			out = append(out, flat.Route.Receiver)
+		}
+		return out
+	}
+
+	assert.NoError(t, api.InsertRoute(ctx, RoutePath{}, -1, &AlertManagerRoute{Receiver: "a"}))
+	assert.NoError(t, api.InsertRoute(ctx, RoutePath{}, 0, &AlertManagerRoute{Receiver: "b"}))
+	assert.NoError(t, api.InsertRoute(ctx, RoutePath{1}, 0, &AlertManagerRoute{Receiver: "a.1"}))
+	assert.Equal(t, []string{"web.hook", "b", "a", "a.1"}, receivers())
+
+	assert.NoError(t, api.MoveRoute(ctx, RoutePath{0}, RoutePath{1}, 0))
+	assert.Equal(t, []string{"web.hook", "a", "b", "a.1"}, receivers())
+	assert.Error(t, api.MoveRoute(ctx, RoutePath{0}, RoutePath{0, 1}, 0))
+
+	assert.NoError(t, api.UpdateRoute(ctx, RoutePath{0, 0}, &AlertManagerRoute{Receiver: "c"}))
+	route, err := api.GetRoute(RoutePath{0, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, "c", route.Receiver)
+
+	assert.NoError(t, api.RemoveRoute(ctx, RoutePath{0, 0}))
+	assert.Equal(t, []string{"web.hook", "a", "a.1"}, receivers())
+	assert.Error(t, api.RemoveRoute(ctx, RoutePath{}))
+	assert.True(t, errors.Is(api.RemoveRoute(ctx, RoutePath{3}), ErrNotFound))
+}
+
+func TestAlertManagerAPI_MoveRouteWithinParent(t *testing.T) {
+	api := newAlertmanagerTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ctx := context.Background()
+
+	receivers := func() []string {
+		var out []string
+		for _, flat := range api.Routes()[1:] {
+			out = append(out, flat.Route.Receiver)
+		}
+		return out
+	}
+	for _, name := range []string{"x", "y", "z"} {
+		assert.NoError(t, api.InsertRoute(ctx, RoutePath{}, -1, &AlertManagerRoute{Receiver: name}))
+	}
+
+	// pos is the index before the move, x lands before z
+	assert.NoError(t, api.MoveRoute(ctx, RoutePath{0}, RoutePath{}, 2))
+	assert.Equal(t, []string{"y", "x", "z"}, receivers())
+
+	assert.NoError(t, api.MoveRoute(ctx, RoutePath{2}, RoutePath{}, 0))
+	assert.Equal(t, []string{"z", "y", "x"}, receivers())
+
+	assert.NoError(t, api.MoveRoute(ctx, RoutePath{0}, RoutePath{}, 3))
+	assert.Equal(t, []string{"y", "x", "z"}, receivers())
+
+	assert.NoError(t, api.MoveRoute(ctx, RoutePath{1}, RoutePath{}, 1))
+	assert.Equal(t, []string{"y", "x", "z"}, receivers())
+
+	assert.NoError(t, api.MoveRoute(ctx, RoutePath{0}, RoutePath{}, -1))
+	assert.Equal(t, []string{"x", "z", "y"}, receivers())
+}
+
+func TestAlertManagerYAML_MatchRoutes(t *testing.T) {
+	yml := &AlertManagerYAML{
+		Route: AlertManagerRoute{
+			Receiver: "default",
+			GroupBy:  []string{"alertname"},
+			Routes: []*AlertManagerRoute{
+				{
+					Receiver: "database",
+					Match:    map[string]string{"service": "mysql"},
+					GroupBy:  []string{"alertname", "cluster"},
+					Continue: true,
+					Routes: []*AlertManagerRoute{
+						{Receiver: "database-pager", Matchers: []string{`severity=~"critical|page"`}},
+					},
+				},
+				{Receiver: "audit", MatchRe: map[string]string{"service": "mysql|postgres"}},
+				{Receiver: "never", Matchers: []string{`service!=""`}},
+			},
+		},
+	}
+
+	matches, err := yml.MatchRoutes(map[string]string{"alertname": "Down", "service": "mysql", "severity": "critical", "cluster": "c1"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, matches, 2) {
+		return
+	}
+	assert.Equal(t, RoutePath{0, 0}, matches[0].Path)
+	assert.Equal(t, "database-pager", matches[0].Receiver)
+	assert.Equal(t, []string{"alertname", "cluster"}, matches[0].GroupBy)
+	assert.Equal(t, map[string]string{"alertname": "Down", "cluster": "c1"}, matches[0].GroupLabels)
+	assert.Equal(t, RoutePath{1}, matches[1].Path)
+	assert.Equal(t, "audit", matches[1].Receiver)
+	assert.Equal(t, map[string]string{"alertname": "Down"}, matches[1].GroupLabels)
+
+	receivers, err := yml.MatchReceivers(map[string]string{"service": "mysql", "severity": "warning"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"database", "audit"}, receivers)
+
+	receivers, err = yml.MatchReceivers(map[string]string{"alertname": "Other"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default"}, receivers)
+
+	yml.Route.Routes[1].MatchRe["service"] = "("
+	_, err = yml.MatchRoutes(map[string]string{"service": "postgres"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	urlpkg "net/url"
+	"time"
+)
+
+type SilenceState string
+
+const (
+	SilenceStateExpired SilenceState = "expired"
+	SilenceStateActive  SilenceState = "active"
+	SilenceStatePending SilenceState = "pending"
+)
+
+type SilenceStatus struct {
+	State SilenceState `json:"state"`
+}
+
+type Silence struct {
+	ID        string    `json:"id,omitempty"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+
+	// Status and UpdatedAt are only set on silences returned by Alertmanager.
+	Status    *SilenceStatus `json:"status,omitempty"`
+	UpdatedAt *time.Time     `json:"updatedAt,omitempty"`
+}
+
+type postSilenceResponse struct {
+	SilenceID string `json:"silenceID"`
+}
+
+func (api *alertManagerAPI) postSilence(ctx context.Context, silence *Silence) (string, error) {
+	in := *silence
+	in.Status = nil
+	in.UpdatedAt = nil
+
+	url := api.URL("/api/v2/silences", map[string]string{})
+
+	var out postSilenceResponse
+	if err := api.doJSON(ctx, http.MethodPost, url, &in, &out); err != nil {
+		return "", err
+	}
+	return out.SilenceID, nil
+}
+
+func (api *alertManagerAPI) CreateSilence(ctx context.Context, silence *Silence) (string, error) {
+	if silence.ID != "" {
+		return "", errors.New("silence id must be empty when creating a silence")
+	}
+	return api.postSilence(ctx, silence)
+}
+
+func (api *alertManagerAPI) ListSilences(ctx context.Context, matchers ...Matcher) ([]Silence, error) {
+	url := api.URL("/api/v2/silences", map[string]string{})
+	if len(matchers) != 0 {
+		query := urlpkg.Values{}
+		for _, m := range matchers {
+			query.Add("filter", m.String())
+		}
+		url.RawQuery = query.Encode()
+	}
+
+	var out []Silence
+	if err := api.doJSON(ctx, http.MethodGet, url, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (api *alertManagerAPI) GetSilence(ctx context.Context, id string) (*Silence, error) {
+	url := api.URL("/api/v2/silence/:id", map[string]string{"id": id})
+
+	var out Silence
+	if err := api.doJSON(ctx, http.MethodGet, url, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateSilence updates the silence identified by silence.ID. Alertmanager may
+// expire the old silence and create a new one, so the returned id can differ.
+func (api *alertManagerAPI) UpdateSilence(ctx context.Context, silence *Silence) (string, error) {
+	if silence.ID == "" {
+		return "", errors.New("silence id is required")
+	}
+	return api.postSilence(ctx, silence)
+}
+
+func (api *alertManagerAPI) ExpireSilence(ctx context.Context, id string) error {
+	url := api.URL("/api/v2/silence/:id", map[string]string{"id": id})
+	return api.doJSON(ctx, http.MethodDelete, url, nil, nil)
+}
@@ -0,0 +1,121 @@
+package pag
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSilences struct {
+	mu      sync.Mutex
+	next    int
+	items   map[string]*Silence
+	filters []string
+}
+
+func (fs *fakeSilences) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/api/v2/silences" && r.Method == http.MethodPost:
+		var s Silence
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if s.ID == "" {
+			fs.next++
+			s.ID = "silence-" + strconv.Itoa(fs.next)
+		} else if _, ok := fs.items[s.ID]; !ok {
+			http.Error(w, "silence not found", http.StatusNotFound)
+			return
+		}
+		s.Status = &SilenceStatus{State: SilenceStateActive}
+		fs.items[s.ID] = &s
+		_ = json.NewEncoder(w).Encode(map[string]string{"silenceID": s.ID})
+	case r.URL.Path == "/api/v2/silences" && r.Method == http.MethodGet:
+		fs.filters = r.URL.Query()["filter"]
+		out := make([]Silence, 0, len(fs.items))
+		for _, s := range fs.items {
+			out = append(out, *s)
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	case strings.HasPrefix(r.URL.Path, "/api/v2/silence/"):
+		id := strings.TrimPrefix(r.URL.Path, "/api/v2/silence/")
+		s, ok := fs.items[id]
+		if !ok {
+			http.Error(w, "silence not found", http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			s.Status = &SilenceStatus{State: SilenceStateExpired}
+			return
+		}
+		_ = json.NewEncoder(w).Encode(s)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestAlertManagerAPI_Silences(t *testing.T) {
+	fs := &fakeSilences{items: map[string]*Silence{}}
+	api := newAlertmanagerTestAPI(t, fs)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	silence := &Silence{
+		Matchers:  []Matcher{{Name: "alertname", Value: "NodeDown"}},
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		CreatedBy: "tester",
+		Comment:   "maintenance",
+	}
+
+	id, err := api.CreateSilence(ctx, silence)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "silence-1", id)
+
+	got, err := api.GetSilence(ctx, id)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "maintenance", got.Comment)
+	assert.Equal(t, SilenceStateActive, got.Status.State)
+
+	got.Comment = "extended maintenance"
+	got.EndsAt = now.Add(2 * time.Hour)
+	id, err = api.UpdateSilence(ctx, got)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "silence-1", id)
+
+	list, err := api.ListSilences(ctx, Matcher{Name: "alertname", Value: "NodeDown"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, list, 1)
+	assert.Equal(t, "extended maintenance", list[0].Comment)
+	assert.Equal(t, []string{`alertname="NodeDown"`}, fs.filters)
+
+	assert.NoError(t, api.ExpireSilence(ctx, id))
+	assert.Equal(t, SilenceStateExpired, fs.items[id].Status.State)
+
+	_, err = api.GetSilence(ctx, "missing")
+	assert.Error(t, err)
+
+	_, err = api.CreateSilence(ctx, got)
+	assert.Error(t, err)
+	_, err = api.UpdateSilence(ctx, &Silence{})
+	assert.Error(t, err)
+}
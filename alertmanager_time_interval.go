@@ -0,0 +1,306 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AlertManagerTimeIntervalYAML struct {
+	Name string `json:"name"`
+
+	TimeIntervals []TimeIntervalYAML `json:"time_intervals"`
+}
+
+// TimeIntervalYAML matches a point in time when all of its non-empty fields
+// match. Ranges are inclusive and written as "start:end".
+type TimeIntervalYAML struct {
+	Times []TimeRangeYAML `json:"times,omitempty"`
+	// Weekdays such as "monday" or "monday:friday".
+	Weekdays []string `json:"weekdays,omitempty"`
+	// DaysOfMonth such as "1", "1:5" or "-3:-1", negative days count from the end of the month.
+	DaysOfMonth []string `json:"days_of_month,omitempty"`
+	// Months such as "january", "1" or "january:march".
+	Months []string `json:"months,omitempty"`
+	// Years such as "2024" or "2024:2026".
+	Years []string `json:"years,omitempty"`
+	// Location is the time zone name the interval is evaluated in, UTC by default.
+	Location string `json:"location,omitempty"`
+}
+
+// TimeRangeYAML is a range of the day such as 09:00 to 17:00, the end is exclusive.
+type TimeRangeYAML struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+var weekdays = map[string]int{
+	"sunday": 0, "monday": 1, "tuesday": 2, "wednesday": 3, "thursday": 4, "friday": 5, "saturday": 6,
+}
+
+var months = map[string]int{
+	"january": 1, "february": 2, "march": 3, "april": 4, "may": 5, "june": 6,
+	"july": 7, "august": 8, "september": 9, "october": 10, "november": 11, "december": 12,
+}
+
+func (ti *AlertManagerTimeIntervalYAML) Validate() error {
+	if ti.Name == "" {
+		return errors.New("time interval name is required")
+	}
+	for i, interval := range ti.TimeIntervals {
+		if err := interval.Validate(); err != nil {
+			return fmt.Errorf("time interval %q[%d]: %w", ti.Name, i, err)
+		}
+	}
+	return nil
+}
+
+func (ti *TimeIntervalYAML) Validate() error {
+	for _, tr := range ti.Times {
+		start, err := parseMinuteOfDay(tr.StartTime)
+		if err != nil {
+			return err
+		}
+		end, err := parseMinuteOfDay(tr.EndTime)
+		if err != nil {
+			return err
+		}
+		if start >= end {
+			return fmt.Errorf("start_time %s must be before end_time %s", tr.StartTime, tr.EndTime)
+		}
+	}
+
+	for _, s := range ti.Weekdays {
+		if err := validateRange(s, func(v string) (int, error) {
+			return parseNamed(v, weekdays, -1, -1)
+		}); err != nil {
+			return fmt.Errorf("weekdays: %w", err)
+		}
+	}
+	for _, s := range ti.DaysOfMonth {
+		if err := validateDaysOfMonth(s); err != nil {
+			return fmt.Errorf("days_of_month: %w", err)
+		}
+	}
+	for _, s := range ti.Months {
+		if err := validateRange(s, func(v string) (int, error) {
+			return parseNamed(v, months, 1, 12)
+		}); err != nil {
+			return fmt.Errorf("months: %w", err)
+		}
+	}
+	for _, s := range ti.Years {
+		if err := validateRange(s, func(v string) (int, error) {
+			return parseNumber(v, 1, 9999)
+		}); err != nil {
+			return fmt.Errorf("years: %w", err)
+		}
+	}
+
+	if ti.Location != "" {
+		if _, err := time.LoadLocation(ti.Location); err != nil {
+			return fmt.Errorf("location: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// parseMinuteOfDay parses HH:MM between 00:00 and 24:00.
+func parseMinuteOfDay(s string) (int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("bad time %q, expected HH:MM", s)
+	}
+	hour, err := parseNumber(parts[0], 0, 24)
+	if err != nil {
+		return 0, fmt.Errorf("bad time %q: %w", s, err)
+	}
+	minute, err := parseNumber(parts[1], 0, 59)
+	if err != nil {
+		return 0, fmt.Errorf("bad time %q: %w", s, err)
+	}
+	if hour == 24 && minute != 0 {
+		return 0, fmt.Errorf("bad time %q, must not be after 24:00", s)
+	}
+	return hour*60 + minute, nil
+}
+
+func parseNumber(s string, min, max int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad number %q", s)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("%d out of range [%d, %d]", n, min, max)
+	}
+	return n, nil
+}
+
+// parseNamed parses one of names, or a number between min and max when min is not negative.
+func parseNamed(s string, names map[string]int, min, max int) (int, error) {
+	if n, ok := names[strings.ToLower(s)]; ok {
+		return n, nil
+	}
+	if min < 0 {
+		return 0, fmt.Errorf("unknown name %q", s)
+	}
+	return parseNumber(s, min, max)
+}
+
+func validateRange(s string, parse func(string) (int, error)) error {
+	parts := strings.Split(s, ":")
+	if len(parts) > 2 {
+		return fmt.Errorf("bad range %q", s)
+	}
+	start, err := parse(parts[0])
+	if err != nil {
+		return err
+	}
+	if len(parts) == 2 {
+		end, err := parse(parts[1])
+		if err != nil {
+			return err
+		}
+		if start > end {
+			return fmt.Errorf("bad range %q, start is after end", s)
+		}
+	}
+	return nil
+}
+
+func validateDaysOfMonth(s string) error {
+	parts := strings.Split(s, ":")
+	if len(parts) > 2 {
+		return fmt.Errorf("bad range %q", s)
+	}
+
+	var days []int
+	for _, part := range parts {
+		day, err := parseNumber(part, -31, 31)
+		if err != nil {
+			return err
+		}
+		if day == 0 {
+			return fmt.Errorf("bad day %q, days of month start at 1", s)
+		}
+		days = append(days, day)
+	}
+	// a range may go from a day to a day counted from the end of the month,
+	// but not the other way round
+	if len(days) == 2 {
+		start, end := days[0], days[1]
+		if (start < 0 && end > 0) || ((start < 0) == (end < 0) && start > end) {
+			return fmt.Errorf("bad range %q, start is after end", s)
+		}
+	}
+	return nil
+}
+
+// Validate checks the time intervals and that every time interval referenced by a route is defined.
+func (yml *AlertManagerYAML) Validate() error {
+	defined := map[string]bool{}
+	for _, intervals := range [][]AlertManagerTimeIntervalYAML{yml.MuteTimeIntervals, yml.TimeIntervals} {
+		for i := range intervals {
+			if err := intervals[i].Validate(); err != nil {
+				return err
+			}
+			if defined[intervals[i].Name] {
+				return fmt.Errorf("time interval %q: %w", intervals[i].Name, ErrAlreadyExists)
+			}
+			defined[intervals[i].Name] = true
+		}
+	}
+
+	var err error
+	yml.Route.walkPath(nil, func(route *AlertManagerRoute, path RoutePath) {
+		for _, names := range [][]string{route.MuteTimeIntervals, route.ActiveTimeIntervals} {
+			for _, name := range names {
+				if err == nil && !defined[name] {
+					err = fmt.Errorf("route %s: undefined time interval %q", path, name)
+				}
+			}
+		}
+	})
+
+	return err
+}
+
+// timeInterval returns the interval list holding name and its index in it.
+func (yml *AlertManagerYAML) timeInterval(name string) (*[]AlertManagerTimeIntervalYAML, int) {
+	for _, intervals := range []*[]AlertManagerTimeIntervalYAML{&yml.TimeIntervals, &yml.MuteTimeIntervals} {
+		for i, interval := range *intervals {
+			if interval.Name == name {
+				return intervals, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+func (api *alertManagerAPI) GetTimeInterval(name string) (*AlertManagerTimeIntervalYAML, error) {
+	yml := api.ConfigYAML()
+
+	intervals, i := yml.timeInterval(name)
+	if i < 0 {
+		return nil, fmt.Errorf("time interval %q: %w", name, ErrNotFound)
+	}
+	out := (*intervals)[i]
+	return &out, nil
+}
+
+func (api *alertManagerAPI) AddTimeInterval(ctx context.Context, interval *AlertManagerTimeIntervalYAML) error {
+	if err := interval.Validate(); err != nil {
+		return err
+	}
+
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		if _, i := yml.timeInterval(interval.Name); i >= 0 {
+			return fmt.Errorf("time interval %q: %w", interval.Name, ErrAlreadyExists)
+		}
+		yml.TimeIntervals = append(yml.TimeIntervals, *interval)
+		return nil
+	})
+}
+
+func (api *alertManagerAPI) UpdateTimeInterval(ctx context.Context, interval *AlertManagerTimeIntervalYAML) error {
+	if err := interval.Validate(); err != nil {
+		return err
+	}
+
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		intervals, i := yml.timeInterval(interval.Name)
+		if i < 0 {
+			return fmt.Errorf("time interval %q: %w", interval.Name, ErrNotFound)
+		}
+		(*intervals)[i] = *interval
+		return nil
+	})
+}
+
+func (api *alertManagerAPI) DeleteTimeInterval(ctx context.Context, name string) error {
+	return api.UpdateConfigYAML(ctx, func(yml *AlertManagerYAML) error {
+		intervals, i := yml.timeInterval(name)
+		if i < 0 {
+			return fmt.Errorf("time interval %q: %w", name, ErrNotFound)
+		}
+
+		var used bool
+		yml.Route.walk(func(route *AlertManagerRoute) {
+			for _, names := range [][]string{route.MuteTimeIntervals, route.ActiveTimeIntervals} {
+				for _, n := range names {
+					used = used || n == name
+				}
+			}
+		})
+		if used {
+			return fmt.Errorf("time interval %q is still referenced by a route", name)
+		}
+
+		*intervals = append((*intervals)[:i], (*intervals)[i+1:]...)
+		return nil
+	})
+}
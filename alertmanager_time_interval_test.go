@@ -0,0 +1,73 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeIntervalYAML_Validate(t *testing.T) {
+	valid := TimeIntervalYAML{
+		Times:       []TimeRangeYAML{{StartTime: "09:00", EndTime: "24:00"}},
+		Weekdays:    []string{"monday:friday", "Sunday"},
+		DaysOfMonth: []string{"1:5", "-3:-1", "10:-1"},
+		Months:      []string{"january:march", "12"},
+		Years:       []string{"2024:2026"},
+		Location:    "UTC",
+	}
+	assert.NoError(t, valid.Validate())
+
+	invalid := []TimeIntervalYAML{
+		{Times: []TimeRangeYAML{{StartTime: "17:00", EndTime: "09:00"}}},
+		{Times: []TimeRangeYAML{{StartTime: "9", EndTime: "10:00"}}},
+		{Weekdays: []string{"friday:monday"}},
+		{Weekdays: []string{"someday"}},
+		{DaysOfMonth: []string{"0"}},
+		{DaysOfMonth: []string{"-1:5"}},
+		{Months: []string{"13"}},
+		{Years: []string{"2026:2024"}},
+		{Location: "Nowhere/City"},
+	}
+	for _, ti := range invalid {
+		assert.Error(t, ti.Validate(), "%+v", ti)
+	}
+}
+
+func TestAlertManagerAPI_TimeIntervals(t *testing.T) {
+	api := newAlertmanagerTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ctx := context.Background()
+
+	interval := &AlertManagerTimeIntervalYAML{
+		Name: "offhours",
+		TimeIntervals: []TimeIntervalYAML{
+			{Times: []TimeRangeYAML{{StartTime: "00:00", EndTime: "08:00"}}},
+		},
+	}
+	assert.NoError(t, api.AddTimeInterval(ctx, interval))
+	assert.True(t, errors.Is(api.AddTimeInterval(ctx, interval), ErrAlreadyExists))
+
+	interval.TimeIntervals[0].Weekdays = []string{"saturday:sunday"}
+	assert.Error(t, api.UpdateTimeInterval(ctx, interval))
+	interval.TimeIntervals[0].Weekdays = []string{"sunday", "saturday"}
+	assert.NoError(t, api.UpdateTimeInterval(ctx, interval))
+
+	got, err := api.GetTimeInterval("offhours")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"sunday", "saturday"}, got.TimeIntervals[0].Weekdays)
+
+	// routes may only refer to defined intervals
+	err = api.InsertRoute(ctx, RoutePath{}, -1, &AlertManagerRoute{Receiver: "web.hook", MuteTimeIntervals: []string{"holidays"}})
+	assert.Error(t, err)
+	assert.NoError(t, api.InsertRoute(ctx, RoutePath{}, -1, &AlertManagerRoute{Receiver: "web.hook", MuteTimeIntervals: []string{"offhours"}}))
+
+	assert.Error(t, api.DeleteTimeInterval(ctx, "offhours"))
+	assert.NoError(t, api.RemoveRoute(ctx, RoutePath{0}))
+	assert.NoError(t, api.DeleteTimeInterval(ctx, "offhours"))
+	_, err = api.GetTimeInterval("offhours")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -1,6 +1,8 @@
 package pag
 
 import (
+	"context"
+	"errors"
 	"net/http"
 )
 
@@ -34,3 +36,29 @@ func (c *Client) AlertManager() (AlertManagerAPI, error) {
 func (c *Client) Grafana() (GrafanaAPI, error) {
 	return NewGrafanaAPI(c.hc, c.cfg.Grafana)
 }
+
+// EnsureAlertManager makes sure Prometheus sends its alerts to the
+// Alertmanager at AlertManagerConfig.Endpoint. An alertmanagers entry is added
+// to the Prometheus configuration, and Prometheus reloaded, only if none of the
+// statically configured Alertmanagers is that one.
+func (c *Client) EnsureAlertManager(ctx context.Context) error {
+	if c.cfg.AlertManager == nil || c.cfg.AlertManager.Endpoint == "" {
+		return errors.New("alertManager endpoint is required")
+	}
+	endpoint := c.cfg.AlertManager.Endpoint
+
+	pa, err := c.Prometheus()
+	if err != nil {
+		return err
+	}
+
+	py := pa.ConfigYAML()
+	ok, err := py.HasAlertManager(endpoint)
+	if err != nil || ok {
+		return err
+	}
+
+	return pa.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		return py.AddAlertManager(endpoint)
+	})
+}
@@ -0,0 +1,131 @@
+package pag
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when the requested configuration item does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when adding a configuration item whose name is already taken.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrAdminAPIDisabled is returned by the TSDB admin operations when Prometheus
+	// runs without --web.enable-admin-api.
+	ErrAdminAPIDisabled = errors.New("prometheus admin APIs disabled")
+)
+
+// StatusError is a response of Prometheus or Alertmanager with a status code
+// other than 2xx. The more specific errors below wrap it, so errors.As with a
+// *StatusError matches all of them.
+type StatusError struct {
+	// Service is "prometheus" or "alertmanager".
+	Service    string
+	Method     string
+	Path       string
+	StatusCode int
+	// Message is the body of the response, usually the reason given by the server.
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s %s: %d %s", e.Service, e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// UnauthorizedError is returned when the server rejects the credentials of
+// the request with 401 Unauthorized or 403 Forbidden.
+type UnauthorizedError struct {
+	StatusError
+}
+
+func (e *UnauthorizedError) Unwrap() error {
+	return &e.StatusError
+}
+
+// UnhealthyError is returned by Healthy when the server reports it is not healthy.
+type UnhealthyError struct {
+	StatusError
+}
+
+func (e *UnhealthyError) Unwrap() error {
+	return &e.StatusError
+}
+
+// NotReadyError is returned by Ready when the server is not ready to serve
+// traffic yet, e.g. while Prometheus replays its WAL.
+type NotReadyError struct {
+	StatusError
+}
+
+func (e *NotReadyError) Unwrap() error {
+	return &e.StatusError
+}
+
+// ReloadFailedError is returned by Reload when the server did not apply the
+// configuration, Message holds the reason, e.g. the configuration error or
+// that the lifecycle API of Prometheus is not enabled.
+type ReloadFailedError struct {
+	StatusError
+}
+
+func (e *ReloadFailedError) Unwrap() error {
+	return &e.StatusError
+}
+
+// responseError returns nil for a 2xx response. Otherwise it returns an
+// *UnauthorizedError for 401 and 403 responses, and for any other status the
+// error typed builds from the StatusError, or the *StatusError itself when
+// typed is nil.
+func responseError(service string, req *http.Request, resp *http.Response, body []byte, typed func(StatusError) error) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	e := StatusError{
+		Service:    service,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+	switch {
+	// Prometheus answers a reload with 403 when the lifecycle API is disabled
+	case resp.StatusCode == http.StatusForbidden && strings.Contains(e.Message, "Lifecycle API is not enabled"):
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return &UnauthorizedError{e}
+	}
+	if typed != nil {
+		return typed(e)
+	}
+	return &e
+}
+
+// ReloadNotAppliedError is returned by ReloadAndVerify when the server accepted
+// the reload but did not report the configuration as running, either because
+// it reports the last reload as unsuccessful or because the timeout expired.
+type ReloadNotAppliedError struct {
+	// Service is "prometheus" or "alertmanager".
+	Service string
+	// Reason is what the last check of the running configuration found.
+	Reason string
+	// Err is the error of the last check, or the context error on timeout.
+	Err error
+}
+
+func (e *ReloadNotAppliedError) Error() string {
+	msg := e.Service + " reload not applied: " + e.Reason
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ReloadNotAppliedError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,206 @@
+package pag
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/prometheus/common/config"
+	"sigs.k8s.io/yaml"
+)
+
+// marshalYAML marshals v like yaml.Marshal, but keeps the real value of
+// config.Secret fields instead of "<secret>" so the output can be written back
+// to a configuration file. It does not set config.MarshalSecretValue, which
+// would reveal the secrets of anything else marshaled in the process
+// meanwhile, but puts the values collected from v back in place of the masks.
+func marshalYAML(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []secretValue
+	collectSecrets(reflect.ValueOf(v), nil, &secrets)
+	if len(secrets) == 0 {
+		return yaml.JSONToYAML(data)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err = dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	for _, s := range secrets {
+		tree = revealSecret(tree, s.path, s.value)
+	}
+	if data, err = json.Marshal(tree); err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(data)
+}
+
+// secretValue is a non-empty config.Secret and its path in the JSON encoding,
+// made of object keys and array indexes.
+type secretValue struct {
+	path  []any
+	value string
+}
+
+var (
+	secretType           = reflect.TypeOf(config.Secret(""))
+	httpClientConfigType = reflect.TypeOf(config.HTTPClientConfig{})
+)
+
+func collectSecrets(v reflect.Value, path []any, out *[]secretValue) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			collectSecrets(v.Elem(), path, out)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			name, inline, ok := jsonField(f)
+			switch {
+			case !ok:
+			case inline:
+				collectSecrets(v.Field(i), path, out)
+			default:
+				collectSecrets(v.Field(i), appendPath(path, name), out)
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			collectSecrets(iter.Value(), appendPath(path, iter.Key().String()), out)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectSecrets(v.Index(i), appendPath(path, i), out)
+		}
+	case reflect.String:
+		if v.Type() == secretType && v.Len() > 0 {
+			*out = append(*out, secretValue{path: path, value: v.String()})
+		}
+	}
+}
+
+// jsonField returns the key of the struct field in the JSON encoding, or
+// whether its fields are inlined in the parent object. Besides embedded
+// structs, this covers the HTTPClientConfig fields this package inlines in
+// MarshalJSON and the ",inline" maps prometheus/common inlines the same way.
+func jsonField(f reflect.StructField) (name string, inline, ok bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", f.Type == httpClientConfigType, f.Type == httpClientConfigType
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	if name != "" {
+		return name, false, true
+	}
+	if f.Anonymous || strings.Contains(f.Tag.Get("yaml"), ",inline") {
+		return "", true, true
+	}
+	return f.Name, false, true
+}
+
+func appendPath(path []any, elem any) []any {
+	return append(path[:len(path):len(path)], elem)
+}
+
+// revealSecret replaces the mask at path in the decoded JSON tree with value.
+func revealSecret(node any, path []any, value string) any {
+	if len(path) == 0 {
+		if node == "<secret>" {
+			return value
+		}
+		return node
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		if key, ok := path[0].(string); ok {
+			if child, ok := n[key]; ok {
+				n[key] = revealSecret(child, path[1:], value)
+			}
+		}
+	case []any:
+		if i, ok := path[0].(int); ok && i < len(n) {
+			n[i] = revealSecret(n[i], path[1:], value)
+		}
+	}
+	return node
+}
+
+// writeFileAtomic writes data to a temporary file in the directory of dst and
+// renames it over dst, so readers never observe a partially written file.
+func writeFileAtomic(dst string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer func() {
+		_ = os.Remove(tmp)
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, dst)
+}
+
+// replaceFile atomically replaces the content of dst with data, keeping the
+// previous content in dst + ".bak". The returned restore function puts the
+// previous content back in place.
+func replaceFile(dst string, data []byte) (restore func() error, err error) {
+	perm := os.FileMode(0644)
+	old, err := os.ReadFile(dst)
+	switch {
+	case err == nil:
+		if fi, err := os.Stat(dst); err == nil {
+			perm = fi.Mode().Perm()
+		}
+		if err = writeFileAtomic(dst+".bak", old, perm); err != nil {
+			return nil, err
+		}
+		restore = func() error {
+			return writeFileAtomic(dst, old, perm)
+		}
+	case os.IsNotExist(err):
+		restore = func() error {
+			return os.Remove(dst)
+		}
+	default:
+		return nil, err
+	}
+
+	if err = writeFileAtomic(dst, data, perm); err != nil {
+		return nil, err
+	}
+
+	return restore, nil
+}
@@ -0,0 +1,201 @@
+package pag
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	urlpkg "net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HTTPSDServer is an http.Handler serving the target groups registered with it
+// in the Prometheus HTTP SD format, so Prometheus can discover them through
+// http_sd_configs without a file shared with this process. The last element of
+// the request path is the path escaped job, e.g. a server mounted on "/sd/"
+// serves the groups of the job "node" on "/sd/node" and of "a/b" on "/sd/a%2Fb".
+type HTTPSDServer struct {
+	mu sync.RWMutex
+	// groups are the endpoints of the target groups by job and group name.
+	groups map[string]map[string][]ServiceDiscoveryEndpoint
+}
+
+func NewHTTPSDServer() *HTTPSDServer {
+	return &HTTPSDServer{groups: map[string]map[string][]ServiceDiscoveryEndpoint{}}
+}
+
+// httpSDTargetGroup is a target group in the Prometheus HTTP SD format.
+type httpSDTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+func (s *HTTPSDServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// the job is taken from the escaped path, AddHTTPSDJob escapes a "/" in it
+	escaped := r.URL.EscapedPath()
+	job, err := urlpkg.PathUnescape(escaped[strings.LastIndex(escaped, "/")+1:])
+	if err != nil || job == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	out := []httpSDTargetGroup{}
+	for _, sd := range s.ListTargetGroups(job) {
+		for _, ep := range sd.Endpoints {
+			out = append(out, httpSDTargetGroup{Targets: ep.Targets, Labels: ep.Labels})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// SetTargetGroup registers sd under sd.Job, replacing the group of the same name.
+func (s *HTTPSDServer) SetTargetGroup(sd *ServiceDiscovery) error {
+	if sd.Job == "" {
+		return fmt.Errorf("target group %q: job is required", sd.Name)
+	}
+	if sd.Name == "" {
+		return fmt.Errorf("bad target group name %q", sd.Name)
+	}
+
+	var endpoints []ServiceDiscoveryEndpoint
+	for _, ep := range copyEndpoints(sd.Endpoints) {
+		endpoints = addTargets(endpoints, ep.Labels, ep.Targets)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobGroups(sd.Job)[sd.Name] = endpoints
+	return nil
+}
+
+func (s *HTTPSDServer) jobGroups(job string) map[string][]ServiceDiscoveryEndpoint {
+	groups, ok := s.groups[job]
+	if !ok {
+		groups = map[string][]ServiceDiscoveryEndpoint{}
+		s.groups[job] = groups
+	}
+	return groups
+}
+
+// ListTargetGroups returns the groups registered for the job, sorted by name.
+func (s *HTTPSDServer) ListTargetGroups(job string) []ServiceDiscovery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]ServiceDiscovery, 0, len(s.groups[job]))
+	for name, endpoints := range s.groups[job] {
+		out = append(out, ServiceDiscovery{
+			Name:      name,
+			Job:       job,
+			Endpoints: copyEndpoints(endpoints),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func (s *HTTPSDServer) GetTargetGroup(job, name string) (*ServiceDiscovery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	endpoints, ok := s.groups[job][name]
+	if !ok {
+		return nil, fmt.Errorf("target group %q: %w", name, ErrNotFound)
+	}
+	return &ServiceDiscovery{Name: name, Job: job, Endpoints: copyEndpoints(endpoints)}, nil
+}
+
+// AddTargets adds targets to the group, under the endpoint with exactly the
+// given labels. The group is created if needed.
+func (s *HTTPSDServer) AddTargets(job, name string, labels map[string]string, targets ...string) error {
+	if job == "" || name == "" {
+		return fmt.Errorf("bad target group %q of job %q", name, job)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	groups := s.jobGroups(job)
+	groups[name] = addTargets(groups[name], copyLabels(labels), targets)
+	return nil
+}
+
+// RemoveTargets removes targets from the group, dropping endpoints left empty.
+func (s *HTTPSDServer) RemoveTargets(job, name string, targets ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	endpoints, ok := s.groups[job][name]
+	if !ok {
+		return fmt.Errorf("target group %q: %w", name, ErrNotFound)
+	}
+	s.groups[job][name], _ = removeTargets(endpoints, targets)
+	return nil
+}
+
+func (s *HTTPSDServer) DeleteTargetGroup(job, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.groups[job][name]; !ok {
+		return fmt.Errorf("target group %q: %w", name, ErrNotFound)
+	}
+	delete(s.groups[job], name)
+	if len(s.groups[job]) == 0 {
+		delete(s.groups, job)
+	}
+	return nil
+}
+
+func copyEndpoints(endpoints []ServiceDiscoveryEndpoint) []ServiceDiscoveryEndpoint {
+	out := make([]ServiceDiscoveryEndpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		out = append(out, ServiceDiscoveryEndpoint{
+			Targets: append([]string(nil), ep.Targets...),
+			Labels:  copyLabels(ep.Labels),
+		})
+	}
+	return out
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
+
+// AddHTTPSDJob adds a scrape config for the job discovering its targets from
+// the HTTPSDServer at serverURL, i.e. the URL the server is mounted on.
+func (py *PrometheusYAML) AddHTTPSDJob(jobName, serverURL string) error {
+	if jobName == "" {
+		return fmt.Errorf("job_name is required")
+	}
+	if py.scrapeConfigIndex(jobName) >= 0 {
+		return fmt.Errorf("scrape config %q: %w", jobName, ErrAlreadyExists)
+	}
+
+	py.ScrapeConfigs = append(py.ScrapeConfigs, PrometheusScrapeConfigYAML{
+		JobName: jobName,
+		HTTPSDConfigs: []HTTPSDConfig{
+			{URL: strings.TrimRight(serverURL, "/") + "/" + urlpkg.PathEscape(jobName)},
+		},
+	})
+	return nil
+}
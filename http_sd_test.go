@@ -0,0 +1,103 @@
+package pag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSDServer(t *testing.T) {
+	s := NewHTTPSDServer()
+	mux := http.NewServeMux()
+	mux.Handle("/sd/", s)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	get := func(job string) []map[string]any {
+		resp, err := http.Get(ts.URL + "/sd/" + job)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		var out []map[string]any
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		return out
+	}
+
+	assert.Equal(t, []map[string]any{}, get("node"))
+
+	assert.NoError(t, s.SetTargetGroup(&ServiceDiscovery{
+		Name: "db",
+		Job:  "node",
+		Endpoints: []ServiceDiscoveryEndpoint{
+			{Targets: []string{"db1:9100"}, Labels: map[string]string{"role": "db"}},
+		},
+	}))
+	assert.Error(t, s.SetTargetGroup(&ServiceDiscovery{Name: "db"}))
+	assert.NoError(t, s.AddTargets("node", "web", nil, "web1:9100", "web2:9100"))
+	assert.NoError(t, s.AddTargets("node", "db", map[string]string{"role": "db"}, "db2:9100"))
+
+	assert.Equal(t, []map[string]any{
+		{"targets": []any{"db1:9100", "db2:9100"}, "labels": map[string]any{"role": "db"}},
+		{"targets": []any{"web1:9100", "web2:9100"}},
+	}, get("node"))
+	assert.Empty(t, get("blackbox"))
+
+	assert.NoError(t, s.RemoveTargets("node", "web", "web1:9100"))
+	sd, err := s.GetTargetGroup("node", "web")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web2:9100"}, sd.Endpoints[0].Targets)
+	assert.True(t, errors.Is(s.RemoveTargets("node", "missing", "x"), ErrNotFound))
+
+	assert.NoError(t, s.DeleteTargetGroup("node", "web"))
+	assert.True(t, errors.Is(s.DeleteTargetGroup("node", "web"), ErrNotFound))
+	assert.Len(t, s.ListTargetGroups("node"), 1)
+
+	// a "/" in the job is path escaped, the mount root is not a job
+	assert.NoError(t, s.AddTargets("a/b", "web", nil, "web3:9100"))
+	assert.NoError(t, s.AddTargets("b", "web", nil, "web4:9100"))
+	assert.Equal(t, []map[string]any{{"targets": []any{"web3:9100"}}}, get("a%2Fb"))
+	resp, err := http.Get(ts.URL + "/sd/")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	resp, err = http.Post(ts.URL+"/sd/node", "application/json", nil)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
+
+func TestPrometheusAPI_AddHTTPSDJob(t *testing.T) {
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ctx := context.Background()
+
+	add := func(py *PrometheusYAML) error {
+		return py.AddHTTPSDJob("node", "http://pag:8080/sd/")
+	}
+	assert.NoError(t, api.UpdateConfigYAML(ctx, add))
+	assert.True(t, errors.Is(api.UpdateConfigYAML(ctx, add), ErrAlreadyExists))
+
+	sc, err := api.GetScrapeConfig("node")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "http://pag:8080/sd/node", sc.HTTPSDConfigs[0].URL)
+	}
+
+	assert.NoError(t, api.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		return py.AddHTTPSDJob("a/b", "http://pag:8080/sd")
+	}))
+	sc, err = api.GetScrapeConfig("a/b")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "http://pag:8080/sd/a%2Fb", sc.HTTPSDConfigs[0].URL)
+	}
+}
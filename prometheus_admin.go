@@ -0,0 +1,49 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// adminError wraps the error Prometheus returns for the admin operations when
+// the admin API is disabled with ErrAdminAPIDisabled. Prometheus answers with
+// 503 Service Unavailable, whose body the client keeps in Detail.
+func adminError(err error) error {
+	var apiErr *prometheusv1.Error
+	if errors.As(err, &apiErr) &&
+		(strings.Contains(apiErr.Msg, "admin APIs disabled") || strings.Contains(apiErr.Detail, "admin APIs disabled")) {
+		return fmt.Errorf("%w: %w", ErrAdminAPIDisabled, err)
+	}
+	return err
+}
+
+// Snapshot creates a snapshot of the TSDB in the snapshots directory of the
+// Prometheus data directory and returns its name. The head block, i.e. the
+// data not yet compacted into a block, is left out when skipHead is set.
+func (pa *prometheusAPI) Snapshot(ctx context.Context, skipHead bool) (string, error) {
+	res, err := pa.newAPI().Snapshot(ctx, skipHead)
+	if err != nil {
+		return "", adminError(err)
+	}
+	return res.Name, nil
+}
+
+// DeleteSeries deletes the data of the series matching any of the selectors
+// between start and end, the whole retention when they are zero. The data is
+// only removed from disk by the next compaction or CleanTombstones.
+func (pa *prometheusAPI) DeleteSeries(ctx context.Context, matches []string, start, end time.Time) error {
+	if len(matches) == 0 {
+		return errors.New("at least one series selector is required")
+	}
+	return adminError(pa.newAPI().DeleteSeries(ctx, matches, start, end))
+}
+
+// CleanTombstones removes the data deleted by DeleteSeries from disk.
+func (pa *prometheusAPI) CleanTombstones(ctx context.Context) error {
+	return adminError(pa.newAPI().CleanTombstones(ctx))
+}
@@ -0,0 +1,74 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPrometheusAdminTestAPI(t *testing.T, enabled bool, requests *[]*http.Request) PrometheusAPI {
+	return newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		*requests = append(*requests, r)
+
+		w.Header().Set("Content-Type", "application/json")
+		if !enabled {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"unavailable","error":"admin APIs disabled"}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v1/admin/tsdb/snapshot":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"name":"20240101T000000Z-0123456789abcdef"}}`))
+		case "/api/v1/admin/tsdb/delete_series", "/api/v1/admin/tsdb/clean_tombstones":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestPrometheusAPI_TSDBAdmin(t *testing.T) {
+	var requests []*http.Request
+	api := newPrometheusAdminTestAPI(t, true, &requests)
+	ctx := context.Background()
+
+	name, err := api.Snapshot(ctx, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "20240101T000000Z-0123456789abcdef", name)
+	assert.Equal(t, http.MethodPost, requests[0].Method)
+	assert.Equal(t, "true", requests[0].Form.Get("skip_head"))
+
+	start := time.Unix(1700000000, 0)
+	assert.NoError(t, api.DeleteSeries(ctx, []string{`{user_id="42"}`}, start, time.Time{}))
+	assert.Equal(t, "/api/v1/admin/tsdb/delete_series", requests[1].URL.Path)
+	assert.Equal(t, []string{`{user_id="42"}`}, requests[1].Form["match[]"])
+	assert.Equal(t, "1700000000", requests[1].Form.Get("start"))
+	assert.Empty(t, requests[1].Form.Get("end"))
+
+	assert.Error(t, api.DeleteSeries(ctx, nil, time.Time{}, time.Time{}))
+	assert.Len(t, requests, 2)
+
+	assert.NoError(t, api.CleanTombstones(ctx))
+	assert.Equal(t, "/api/v1/admin/tsdb/clean_tombstones", requests[2].URL.Path)
+}
+
+func TestPrometheusAPI_TSDBAdminDisabled(t *testing.T) {
+	var requests []*http.Request
+	api := newPrometheusAdminTestAPI(t, false, &requests)
+	ctx := context.Background()
+
+	_, err := api.Snapshot(ctx, false)
+	assert.True(t, errors.Is(err, ErrAdminAPIDisabled))
+	var apiErr *prometheusv1.Error
+	assert.True(t, errors.As(err, &apiErr))
+
+	assert.True(t, errors.Is(api.DeleteSeries(ctx, []string{"up"}, time.Time{}, time.Time{}), ErrAdminAPIDisabled))
+	assert.True(t, errors.Is(api.CleanTombstones(ctx), ErrAdminAPIDisabled))
+}
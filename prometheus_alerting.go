@@ -0,0 +1,161 @@
+package pag
+
+import (
+	"fmt"
+	urlpkg "net/url"
+	"strings"
+
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+)
+
+type PrometheusAlertingYAML struct {
+	// Relabel alerts before sending them to any Alertmanager.
+	AlertRelabelConfigs []RelabelConfigYAML `json:"alert_relabel_configs,omitempty"`
+
+	AlertManagers []PrometheusAlertManagerYAML `json:"alertmanagers,omitempty"`
+}
+
+type PrometheusAlertManagerYAML struct {
+	HTTPClientConfig config.HTTPClientConfig `json:"-"`
+	// Configures AWS's Signature Verification 4 signing process to sign requests.
+	SigV4 *SigV4YAML `json:"sigv4,omitempty"`
+
+	// The URL scheme to use when talking to Alertmanagers, http by default.
+	Scheme string `json:"scheme,omitempty"`
+	// Path prefix to add in front of the push endpoint path.
+	PathPrefix string `json:"path_prefix,omitempty"`
+	// The timeout used when sending alerts.
+	Timeout model.Duration `json:"timeout,omitempty"`
+	// The api version of Alertmanager, v2 by default.
+	APIVersion string `json:"api_version,omitempty"`
+
+	StaticConfig []ServiceDiscoveryEndpoint `json:"static_configs,omitempty"`
+
+	FileSDConfigs []FileSDConfig `json:"file_sd_configs,omitempty"`
+
+	// List of Alertmanager relabel configurations.
+	RelabelConfigs []RelabelConfigYAML `json:"relabel_configs,omitempty"`
+	// Relabel alerts before sending them to this Alertmanager.
+	AlertRelabelConfigs []RelabelConfigYAML `json:"alert_relabel_configs,omitempty"`
+}
+
+func (c PrometheusAlertManagerYAML) MarshalJSON() ([]byte, error) {
+	type plain PrometheusAlertManagerYAML
+	return marshalWithHTTPClientConfig(plain(c), c.HTTPClientConfig)
+}
+
+func (c *PrometheusAlertManagerYAML) UnmarshalJSON(data []byte) error {
+	type plain PrometheusAlertManagerYAML
+	return unmarshalWithHTTPClientConfig(data, (*plain)(c), &c.HTTPClientConfig)
+}
+
+func (c *PrometheusAlertManagerYAML) Validate() error {
+	switch c.Scheme {
+	case "", "http", "https":
+	default:
+		return fmt.Errorf("invalid scheme %q", c.Scheme)
+	}
+	switch c.APIVersion {
+	case "", "v1", "v2":
+	default:
+		return fmt.Errorf("expected Alertmanager api version to be one of [v1 v2] but got %q", c.APIVersion)
+	}
+	if err := c.HTTPClientConfig.Validate(); err != nil {
+		return err
+	}
+	if _, err := relabelConfigs(c.RelabelConfigs); err != nil {
+		return fmt.Errorf("relabel_configs: %w", err)
+	}
+	if _, err := relabelConfigs(c.AlertRelabelConfigs); err != nil {
+		return fmt.Errorf("alert_relabel_configs: %w", err)
+	}
+	return nil
+}
+
+func (a *PrometheusAlertingYAML) Validate() error {
+	if _, err := relabelConfigs(a.AlertRelabelConfigs); err != nil {
+		return fmt.Errorf("alert_relabel_configs: %w", err)
+	}
+	for i := range a.AlertManagers {
+		if err := a.AlertManagers[i].Validate(); err != nil {
+			return fmt.Errorf("alertmanagers %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// alertManagerTarget splits an Alertmanager endpoint, with or without a
+// scheme, into the scheme, the target address and the path prefix.
+func alertManagerTarget(endpoint string) (scheme, target, pathPrefix string, err error) {
+	if !strings.HasPrefix(endpoint, "http") {
+		endpoint = "http://" + endpoint
+	}
+	u, err := urlpkg.Parse(endpoint)
+	if err != nil {
+		return "", "", "", err
+	}
+	if u.Host == "" {
+		return "", "", "", fmt.Errorf("invalid alertmanager endpoint %q", endpoint)
+	}
+	return u.Scheme, u.Host, strings.Trim(u.Path, "/"), nil
+}
+
+// HasAlertManager reports whether one of the statically configured
+// Alertmanagers is the one at endpoint.
+func (py *PrometheusYAML) HasAlertManager(endpoint string) (bool, error) {
+	scheme, target, pathPrefix, err := alertManagerTarget(endpoint)
+	if err != nil {
+		return false, err
+	}
+	if py.Alerting == nil {
+		return false, nil
+	}
+
+	for _, am := range py.Alerting.AlertManagers {
+		amScheme := am.Scheme
+		if amScheme == "" {
+			amScheme = "http"
+		}
+		if amScheme != scheme || strings.Trim(am.PathPrefix, "/") != pathPrefix {
+			continue
+		}
+		for _, ep := range am.StaticConfig {
+			for _, t := range ep.Targets {
+				if t == target {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// AddAlertManager adds an alertmanagers entry sending alerts to the
+// Alertmanager at endpoint.
+func (py *PrometheusYAML) AddAlertManager(endpoint string) error {
+	ok, err := py.HasAlertManager(endpoint)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf("alertmanager %q: %w", endpoint, ErrAlreadyExists)
+	}
+
+	scheme, target, pathPrefix, _ := alertManagerTarget(endpoint)
+	am := PrometheusAlertManagerYAML{
+		StaticConfig: []ServiceDiscoveryEndpoint{{Targets: []string{target}}},
+	}
+	if scheme != "http" {
+		am.Scheme = scheme
+	}
+	if pathPrefix != "" {
+		am.PathPrefix = "/" + pathPrefix
+	}
+
+	if py.Alerting == nil {
+		py.Alerting = &PrometheusAlertingYAML{}
+	}
+	py.Alerting.AlertManagers = append(py.Alerting.AlertManagers, am)
+	return nil
+}
@@ -0,0 +1,82 @@
+package pag
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusYAML_AlertManagers(t *testing.T) {
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	py := api.ConfigYAML()
+	if assert.NotNil(t, py.Alerting) && assert.Len(t, py.Alerting.AlertManagers, 1) {
+		assert.Equal(t, []string{"127.0.0.1:5100"}, py.Alerting.AlertManagers[0].StaticConfig[0].Targets)
+	}
+
+	for endpoint, want := range map[string]bool{
+		"127.0.0.1:5100":                     true,
+		"http://127.0.0.1:5100/":             true,
+		"https://127.0.0.1:5100":             false,
+		"127.0.0.1:5100/alertmanager":        false,
+		"http://alertmanager.example.com:80": false,
+	} {
+		got, err := py.HasAlertManager(endpoint)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, endpoint)
+	}
+
+	assert.ErrorIs(t, py.AddAlertManager("127.0.0.1:5100"), ErrAlreadyExists)
+	assert.NoError(t, py.AddAlertManager("https://am.example.com/alertmanager"))
+	am := py.Alerting.AlertManagers[1]
+	assert.Equal(t, "https", am.Scheme)
+	assert.Equal(t, "/alertmanager", am.PathPrefix)
+	ok, _ := py.HasAlertManager("https://am.example.com/alertmanager")
+	assert.True(t, ok)
+
+	py.Alerting.AlertManagers[1].APIVersion = "v3"
+	assert.Error(t, py.Validate())
+}
+
+func TestClient_EnsureAlertManager(t *testing.T) {
+	var reloads int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/-/reload" {
+			reloads++
+		}
+	}))
+	defer srv.Close()
+
+	data, err := os.ReadFile("testdata/prometheus.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "prometheus.yaml")
+	if err = os.WriteFile(dst, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{
+		Prometheus:   &PrometheusConfig{Endpoint: srv.URL, ConfigYAML: dst},
+		AlertManager: &AlertManagerConfig{Endpoint: "127.0.0.1:5100"},
+	}
+	c, _ := NewWithHttpClient(srv.Client(), cfg)
+	ctx := context.Background()
+
+	assert.NoError(t, c.EnsureAlertManager(ctx))
+	assert.Equal(t, 0, reloads)
+
+	cfg.AlertManager.Endpoint = "http://alertmanager:9093"
+	assert.NoError(t, c.EnsureAlertManager(ctx))
+	assert.Equal(t, 1, reloads)
+	assert.NoError(t, c.EnsureAlertManager(ctx))
+	assert.Equal(t, 1, reloads)
+
+	data, _ = os.ReadFile(dst)
+	assert.Contains(t, string(data), "# Alertmanager configuration\nalerting:\n  alertmanagers:\n    - static_configs:\n        - targets:\n            - 127.0.0.1:5100\n    - static_configs:\n        - targets:\n            - alertmanager:9093\n")
+}
@@ -1,13 +1,17 @@
 package pag
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	urlpkg "net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/api"
@@ -20,9 +24,15 @@ import (
 type PrometheusYAML struct {
 	Global PrometheusGlobalYAML `json:"global"`
 
+	Alerting *PrometheusAlertingYAML `json:"alerting,omitempty"`
+
 	RuleFiles []string `json:"rule_files"`
 
 	ScrapeConfigs []PrometheusScrapeConfigYAML `json:"scrape_configs"`
+
+	RemoteWrite []PrometheusRemoteWriteYAML `json:"remote_write,omitempty"`
+
+	RemoteRead []PrometheusRemoteReadYAML `json:"remote_read,omitempty"`
 }
 
 type PrometheusGlobalYAML struct {
@@ -48,10 +58,10 @@ type PrometheusScrapeConfigYAML struct {
 	JobName string `json:"job_name"`
 	// Indicator whether the scraped metrics should remain unmodified.
 	HonorLabels bool `json:"honor_labels,omitempty"`
-	// Indicator whether the scraped timestamps should be respected.
-	HonorTimestamps bool `json:"honor_timestamps"`
-	// Indicator whether to track the staleness of the scraped timestamps.
-	TrackTimestampsStaleness bool `json:"track_timestamps_staleness"`
+	// Indicator whether the scraped timestamps should be respected, true when nil.
+	HonorTimestamps *bool `json:"honor_timestamps,omitempty"`
+	// Indicator whether to track the staleness of the scraped timestamps, false when nil.
+	TrackTimestampsStaleness *bool `json:"track_timestamps_staleness,omitempty"`
 	// A set of query parameters with which the target is scraped.
 	Params urlpkg.Values `json:"params,omitempty"`
 	// How frequently to scrape the targets of this scrape config.
@@ -66,17 +76,105 @@ type PrometheusScrapeConfigYAML struct {
 	MetricsPath string `json:"metrics_path,omitempty"`
 	// The URL scheme with which to fetch metrics from targets.
 	Scheme string `json:"scheme,omitempty"`
-	// Indicator whether to request compressed response from the target.
-	EnableCompression bool `json:"enable_compression"`
+	// Indicator whether to request compressed response from the target, true when nil.
+	EnableCompression *bool `json:"enable_compression,omitempty"`
 
 	// We cannot do proper Go type embedding below as the parser will then parse
 	// values arbitrarily into the overflow maps of further-down types.
 
-	HTTPClientConfig config.HTTPClientConfig `json:",inline"`
+	HTTPClientConfig config.HTTPClientConfig `json:"-"`
 
 	StaticConfig []ServiceDiscoveryEndpoint `json:"static_configs,omitempty"`
 
 	FileSDConfigs []FileSDConfig `json:"file_sd_configs,omitempty"`
+
+	HTTPSDConfigs []HTTPSDConfig `json:"http_sd_configs,omitempty"`
+
+	DNSSDConfigs []DNSSDConfig `json:"dns_sd_configs,omitempty"`
+
+	ConsulSDConfigs []ConsulSDConfig `json:"consul_sd_configs,omitempty"`
+
+	KubernetesSDConfigs []KubernetesSDConfig `json:"kubernetes_sd_configs,omitempty"`
+
+	// List of target relabel configurations.
+	RelabelConfigs []RelabelConfigYAML `json:"relabel_configs,omitempty"`
+	// List of metric relabel configurations.
+	MetricRelabelConfigs []RelabelConfigYAML `json:"metric_relabel_configs,omitempty"`
+}
+
+func (c PrometheusScrapeConfigYAML) MarshalJSON() ([]byte, error) {
+	type plain PrometheusScrapeConfigYAML
+	return marshalWithHTTPClientConfig(plain(c), c.HTTPClientConfig)
+}
+
+func (c *PrometheusScrapeConfigYAML) UnmarshalJSON(data []byte) error {
+	type plain PrometheusScrapeConfigYAML
+	return unmarshalWithHTTPClientConfig(data, (*plain)(c), &c.HTTPClientConfig)
+}
+
+// marshalWithHTTPClientConfig marshals v with the fields of hc inlined, which
+// Prometheus does with a yaml inline tag that encoding/json has no equivalent
+// for. Types with an HTTP client config tag it `json:"-"` and call this and
+// unmarshalWithHTTPClientConfig from their MarshalJSON and UnmarshalJSON.
+//
+// Fields of hc left at their defaults are omitted. A config loaded from YAML
+// starts from config.DefaultHTTPClientConfig while one built in code starts
+// from the zero value, so follow_redirects and enable_http2 both false are
+// taken as never set. Start from config.DefaultHTTPClientConfig to turn either
+// of them off.
+func marshalWithHTTPClientConfig(v any, hc config.HTTPClientConfig) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if !hc.FollowRedirects && !hc.EnableHTTP2 {
+		hc.FollowRedirects = config.DefaultHTTPClientConfig.FollowRedirects
+		hc.EnableHTTP2 = config.DefaultHTTPClientConfig.EnableHTTP2
+	}
+	if reflect.DeepEqual(hc, config.DefaultHTTPClientConfig) {
+		return data, nil
+	}
+
+	var out, fields, defaults map[string]json.RawMessage
+	if err = json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(hc); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(config.DefaultHTTPClientConfig); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &defaults); err != nil {
+		return nil, err
+	}
+
+	for name, value := range fields {
+		switch string(value) {
+		case "null", `""`, "{}", "[]":
+			continue
+		}
+		if def, ok := defaults[name]; ok && bytes.Equal(def, value) {
+			continue
+		}
+		out[name] = value
+	}
+
+	return json.Marshal(out)
+}
+
+// unmarshalWithHTTPClientConfig is the counterpart of marshalWithHTTPClientConfig,
+// v is the config without its methods and hc its HTTP client config field.
+func unmarshalWithHTTPClientConfig(data []byte, v any, hc *config.HTTPClientConfig) error {
+	rv := reflect.ValueOf(v).Elem()
+	rv.Set(reflect.Zero(rv.Type()))
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, hc)
 }
 
 type FileSDConfig struct {
@@ -87,23 +185,95 @@ type FileSDConfig struct {
 
 type PrometheusAPI interface {
 	ConfigYAML() PrometheusYAML
+	// SetConfigYAML replaces the whole Prometheus configuration, see UpdateConfigYAML.
+	SetConfigYAML(ctx context.Context, py *PrometheusYAML) error
+	// UpdateConfigYAML applies fn to a copy of the loaded configuration, writes the
+	// result to PrometheusConfig.ConfigYAML (keeping a ".bak" backup) and reloads
+	// Prometheus. The file is rolled back if the reload fails. Only the changes
+	// are written, keys the model does not know about and comments are kept.
+	UpdateConfigYAML(ctx context.Context, fn func(py *PrometheusYAML) error) error
 	Healthy(ctx context.Context) error
 	Ready(ctx context.Context) error
 	Reload(ctx context.Context) error
+	// ReloadAndVerify reloads Prometheus and waits up to timeout (30s when zero)
+	// until the runtime info reports a successful reload after this one was
+	// triggered or the running configuration changed. It returns a
+	// *ReloadNotAppliedError when that does not happen. The reload time has a
+	// resolution of a second, so reloading an unchanged configuration twice
+	// within a second cannot be verified.
+	ReloadAndVerify(ctx context.Context, timeout time.Duration) error
 
 	Values(ctx context.Context) (model.LabelValues, error)
 
 	Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error)
 	QueryRange(ctx context.Context, query string, rg prometheusv1.Range, opts ...prometheusv1.Option) (model.Value, prometheusv1.Warnings, error)
 	QueryExemplars(ctx context.Context, query string, start, end time.Time) ([]prometheusv1.ExemplarQueryResult, error)
+	Series(ctx context.Context, matches []string, start, end time.Time, opts ...prometheusv1.Option) ([]model.LabelSet, prometheusv1.Warnings, error)
+	LabelNames(ctx context.Context, matches []string, start, end time.Time, opts ...prometheusv1.Option) ([]string, prometheusv1.Warnings, error)
+	LabelValues(ctx context.Context, label string, matches []string, start, end time.Time, opts ...prometheusv1.Option) (model.LabelValues, prometheusv1.Warnings, error)
+	// Metadata returns the metadata of the metric, of all metrics when metric is empty.
+	Metadata(ctx context.Context, metric, limit string) (map[string][]prometheusv1.Metadata, error)
+	// TargetsMetadata returns the metadata of the metrics scraped from the targets matching matchTarget.
+	TargetsMetadata(ctx context.Context, matchTarget, metric, limit string) ([]prometheusv1.MetricMetadata, error)
+	// TSDB returns the cardinality statistics of the head block.
+	TSDB(ctx context.Context, opts ...prometheusv1.Option) (prometheusv1.TSDBResult, error)
+	WalReplay(ctx context.Context) (prometheusv1.WalReplayStatus, error)
+	Runtimeinfo(ctx context.Context) (prometheusv1.RuntimeinfoResult, error)
+	Buildinfo(ctx context.Context) (prometheusv1.BuildinfoResult, error)
+	Flags(ctx context.Context) (prometheusv1.FlagsResult, error)
+	// LoadedConfig returns the configuration Prometheus is running with, which
+	// differs from ConfigYAML until Prometheus is reloaded after a change of the file.
+	LoadedConfig(ctx context.Context) (*PrometheusYAML, error)
+
+	// Snapshot, DeleteSeries and CleanTombstones need Prometheus to run with
+	// --web.enable-admin-api, they return ErrAdminAPIDisabled otherwise.
+	Snapshot(ctx context.Context, skipHead bool) (string, error)
+	DeleteSeries(ctx context.Context, matches []string, start, end time.Time) error
+	CleanTombstones(ctx context.Context) error
+
+	GetScrapeConfig(jobName string) (*PrometheusScrapeConfigYAML, error)
+	AddScrapeConfig(ctx context.Context, sc *PrometheusScrapeConfigYAML) error
+	UpdateScrapeConfig(ctx context.Context, sc *PrometheusScrapeConfigYAML) error
+	RemoveScrapeConfig(ctx context.Context, jobName string) error
+
+	// GetRemoteWrite returns the remote write config with the given name, or
+	// with the given URL for configs without a name. The other remote write and
+	// remote read methods identify configs the same way.
+	GetRemoteWrite(id string) (*PrometheusRemoteWriteYAML, error)
+	AddRemoteWrite(ctx context.Context, rw *PrometheusRemoteWriteYAML) error
+	UpdateRemoteWrite(ctx context.Context, rw *PrometheusRemoteWriteYAML) error
+	RemoveRemoteWrite(ctx context.Context, id string) error
+	GetRemoteRead(id string) (*PrometheusRemoteReadYAML, error)
+	AddRemoteRead(ctx context.Context, rr *PrometheusRemoteReadYAML) error
+	UpdateRemoteRead(ctx context.Context, rr *PrometheusRemoteReadYAML) error
+	RemoveRemoteRead(ctx context.Context, id string) error
 
 	AddTarget(ctx context.Context, sd *ServiceDiscovery) error
+	// ListTargetGroups reads the file_sd target groups of the job from disk.
+	ListTargetGroups(jobName string) ([]ServiceDiscovery, error)
+	GetTargetGroup(jobName, name string) (*ServiceDiscovery, error)
+	// AddTargets adds targets to the group, under the endpoint with exactly the
+	// given labels. The group file is created if needed.
+	AddTargets(ctx context.Context, jobName, name string, labels map[string]string, targets ...string) error
+	// RemoveTargets removes targets from the group, dropping endpoints left empty.
+	RemoveTargets(ctx context.Context, jobName, name string, targets ...string) error
+	// SetTargetLabels moves a target of the group to the endpoint with the given labels.
+	SetTargetLabels(ctx context.Context, jobName, name, target string, labels map[string]string) error
+	DeleteTargetGroup(ctx context.Context, jobName, name string) error
 	Targets(ctx context.Context) (prometheusv1.TargetsResult, error)
 
 	AddRuleGroups(ctx context.Context, rg *RuleGroup) error
+	// ListRuleGroupFiles reads the rule files matched by rule_files from disk.
+	ListRuleGroupFiles() ([]RuleGroupFile, error)
+	// UpdateRuleGroup replaces the group of the same name in the rule file holding it.
+	UpdateRuleGroup(ctx context.Context, rg *RuleGroup) error
+	// DeleteRuleGroup removes the named group from its rule file, and the file
+	// itself when no group is left.
+	DeleteRuleGroup(ctx context.Context, name string) error
 	GetRules(ctx context.Context) (prometheusv1.RulesResult, error)
 
 	Alerts(ctx context.Context) (prometheusv1.AlertsResult, error)
+	AlertManagers(ctx context.Context) (prometheusv1.AlertManagersResult, error)
 }
 
 func NewPrometheusAPI(hc *http.Client, cfg *PrometheusConfig) (PrometheusAPI, error) {
@@ -136,7 +306,10 @@ func NewPrometheusAPI(hc *http.Client, cfg *PrometheusConfig) (PrometheusAPI, er
 type prometheusAPI struct {
 	cfg *PrometheusConfig
 
+	mu sync.RWMutex
 	py *PrometheusYAML
+	// raw is the content of the config file py was loaded from.
+	raw []byte
 
 	c api.Client
 }
@@ -151,6 +324,7 @@ func (pa *prometheusAPI) load() error {
 		return err
 	}
 	pa.py = &py
+	pa.raw = data
 
 	return nil
 }
@@ -160,49 +334,151 @@ func (pa *prometheusAPI) newAPI() prometheusv1.API {
 }
 
 func (pa *prometheusAPI) ConfigYAML() PrometheusYAML {
+	pa.mu.RLock()
+	defer pa.mu.RUnlock()
+
 	return *pa.py
 }
 
-func (pa *prometheusAPI) Healthy(ctx context.Context) error {
-	url := pa.c.URL("/-/healthy", map[string]string{})
+func (pa *prometheusAPI) SetConfigYAML(ctx context.Context, py *PrometheusYAML) error {
+	return pa.UpdateConfigYAML(ctx, func(dst *PrometheusYAML) error {
+		*dst = *py
+		return nil
+	})
+}
 
-	req := &http.Request{
-		Method: http.MethodGet,
-		URL:    url,
+func (pa *prometheusAPI) UpdateConfigYAML(ctx context.Context, fn func(py *PrometheusYAML) error) error {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	py, err := pa.py.clone()
+	if err != nil {
+		return err
+	}
+	if err = fn(py); err != nil {
+		return err
 	}
-	_, _, err := pa.c.Do(ctx, req)
+
+	return pa.save(ctx, py)
+}
+
+// save writes py to the config file and reloads Prometheus, restoring the
+// previous file if the reload fails. The caller must hold pa.mu.
+func (pa *prometheusAPI) save(ctx context.Context, py *PrometheusYAML) error {
+	if err := py.Validate(); err != nil {
+		return err
+	}
+
+	// merge into the original file to keep what PrometheusYAML does not model
+	data, err := mergeYAML(pa.raw, pa.py, py)
+	if err != nil {
+		return err
+	}
+
+	restore, err := replaceFile(pa.cfg.ConfigYAML, data)
 	if err != nil {
 		return err
 	}
-	return err
+
+	if err = pa.Reload(ctx); err != nil {
+		if rerr := restore(); rerr != nil {
+			return fmt.Errorf("reload prometheus: %w (restore %s: %v)", err, pa.cfg.ConfigYAML, rerr)
+		}
+		return fmt.Errorf("reload prometheus: %w", err)
+	}
+	pa.py = py
+	pa.raw = data
+
+	return nil
 }
 
-func (pa *prometheusAPI) Ready(ctx context.Context) error {
-	url := pa.c.URL("/-/ready", map[string]string{})
+func (py *PrometheusYAML) clone() (*PrometheusYAML, error) {
+	data, err := marshalYAML(py)
+	if err != nil {
+		return nil, err
+	}
 
+	var out PrometheusYAML
+	if err = yaml.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// doLifecycle sends a request to a management endpoint of Prometheus and
+// checks the status of the response, see responseError.
+func (pa *prometheusAPI) doLifecycle(ctx context.Context, method, path string, typed func(StatusError) error) error {
 	req := &http.Request{
-		Method: http.MethodGet,
-		URL:    url,
+		Method: method,
+		URL:    pa.c.URL(path, map[string]string{}),
 	}
-	_, _, err := pa.c.Do(ctx, req)
+	resp, body, err := pa.c.Do(ctx, req)
 	if err != nil {
 		return err
 	}
-	return err
+	return responseError("prometheus", req, resp, body, typed)
+}
+
+func (pa *prometheusAPI) Healthy(ctx context.Context) error {
+	return pa.doLifecycle(ctx, http.MethodGet, "/-/healthy", func(e StatusError) error {
+		return &UnhealthyError{e}
+	})
+}
+
+func (pa *prometheusAPI) Ready(ctx context.Context) error {
+	return pa.doLifecycle(ctx, http.MethodGet, "/-/ready", func(e StatusError) error {
+		return &NotReadyError{e}
+	})
 }
 
 func (pa *prometheusAPI) Reload(ctx context.Context) error {
-	url := pa.c.URL("/-/reload", map[string]string{})
+	return pa.doLifecycle(ctx, http.MethodPost, "/-/reload", func(e StatusError) error {
+		return &ReloadFailedError{e}
+	})
+}
 
-	req := &http.Request{
-		Method: http.MethodPost,
-		URL:    url,
+func (pa *prometheusAPI) ReloadAndVerify(ctx context.Context, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultReloadTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	before, err := pa.newAPI().Runtimeinfo(ctx)
+	if err != nil {
+		return err
 	}
-	_, _, err := pa.c.Do(ctx, req)
+	beforeConfig, err := pa.newAPI().Config(ctx)
 	if err != nil {
 		return err
 	}
-	return err
+
+	if err = pa.Reload(ctx); err != nil {
+		return err
+	}
+
+	return verifyReload(ctx, "prometheus", func(ctx context.Context) (bool, string, bool, error) {
+		info, err := pa.newAPI().Runtimeinfo(ctx)
+		if err != nil {
+			return false, "", false, err
+		}
+		// prometheus_config_last_reload_successful
+		if !info.ReloadConfigSuccess {
+			return false, "last configuration reload was unsuccessful", true, nil
+		}
+		if info.LastConfigTime.After(before.LastConfigTime) {
+			return true, "", false, nil
+		}
+
+		cfg, err := pa.newAPI().Config(ctx)
+		if err != nil {
+			return false, "", false, err
+		}
+		if cfg.YAML != beforeConfig.YAML {
+			return true, "", false, nil
+		}
+		return false, fmt.Sprintf("last configuration reload time is still %s", info.LastConfigTime.Format(time.RFC3339)), false, nil
+	})
 }
 
 func (pa *prometheusAPI) Values(ctx context.Context) (model.LabelValues, error) {
@@ -226,55 +502,104 @@ func (pa *prometheusAPI) QueryExemplars(ctx context.Context, query string, start
 	return pa.newAPI().QueryExemplars(ctx, query, start, end)
 }
 
-func (pa *prometheusAPI) AddTarget(ctx context.Context, sd *ServiceDiscovery) error {
-	var dir string
-	for _, sc := range pa.py.ScrapeConfigs {
-		if len(sc.FileSDConfigs) != 0 && len(sc.FileSDConfigs) != 0 {
-			dir = filepath.Dir(sc.FileSDConfigs[0].Files[0])
-			break
-		}
-	}
+func (pa *prometheusAPI) Series(ctx context.Context, matches []string, start, end time.Time, opts ...prometheusv1.Option) ([]model.LabelSet, prometheusv1.Warnings, error) {
+	return pa.newAPI().Series(ctx, matches, start, end, opts...)
+}
 
-	if dir == "" {
-		return fmt.Errorf("no file_sd_configs configured")
-	}
+func (pa *prometheusAPI) LabelNames(ctx context.Context, matches []string, start, end time.Time, opts ...prometheusv1.Option) ([]string, prometheusv1.Warnings, error) {
+	return pa.newAPI().LabelNames(ctx, matches, start, end, opts...)
+}
+
+func (pa *prometheusAPI) LabelValues(ctx context.Context, label string, matches []string, start, end time.Time, opts ...prometheusv1.Option) (model.LabelValues, prometheusv1.Warnings, error) {
+	return pa.newAPI().LabelValues(ctx, label, matches, start, end, opts...)
+}
+
+func (pa *prometheusAPI) Metadata(ctx context.Context, metric, limit string) (map[string][]prometheusv1.Metadata, error) {
+	return pa.newAPI().Metadata(ctx, metric, limit)
+}
+
+func (pa *prometheusAPI) TargetsMetadata(ctx context.Context, matchTarget, metric, limit string) ([]prometheusv1.MetricMetadata, error) {
+	return pa.newAPI().TargetsMetadata(ctx, matchTarget, metric, limit)
+}
+
+func (pa *prometheusAPI) TSDB(ctx context.Context, opts ...prometheusv1.Option) (prometheusv1.TSDBResult, error) {
+	return pa.newAPI().TSDB(ctx, opts...)
+}
+
+func (pa *prometheusAPI) WalReplay(ctx context.Context) (prometheusv1.WalReplayStatus, error) {
+	return pa.newAPI().WalReplay(ctx)
+}
 
-	dst := filepath.Join(dir, sd.Name+".yaml")
-	data, err := yaml.Marshal(sd.Endpoints)
+func (pa *prometheusAPI) Runtimeinfo(ctx context.Context) (prometheusv1.RuntimeinfoResult, error) {
+	return pa.newAPI().Runtimeinfo(ctx)
+}
+
+func (pa *prometheusAPI) Buildinfo(ctx context.Context) (prometheusv1.BuildinfoResult, error) {
+	return pa.newAPI().Buildinfo(ctx)
+}
+
+func (pa *prometheusAPI) Flags(ctx context.Context) (prometheusv1.FlagsResult, error) {
+	return pa.newAPI().Flags(ctx)
+}
+
+func (pa *prometheusAPI) LoadedConfig(ctx context.Context) (*PrometheusYAML, error) {
+	cfg, err := pa.newAPI().Config(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = os.WriteFile(dst, data, 0644)
+
+	var out PrometheusYAML
+	if err = yaml.Unmarshal([]byte(cfg.YAML), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AddTarget writes the target group sd to a file matched by the file_sd_configs
+// of the job sd.Job, or of the first job using file_sd_configs when sd.Job is empty.
+// An existing group of the same name is replaced.
+func (pa *prometheusAPI) AddTarget(ctx context.Context, sd *ServiceDiscovery) error {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	dst, err := pa.targetGroupFile(sd.Job, sd.Name)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return writeTargetGroup(dst, sd.Endpoints)
 }
 
 func (pa *prometheusAPI) Targets(ctx context.Context) (prometheusv1.TargetsResult, error) {
 	return pa.newAPI().Targets(ctx)
 }
 
+// AddRuleGroups writes rg to "<name>.yml" in the directory of the first
+// rule_files pattern, replacing an existing file of that name, and reloads
+// Prometheus. The file is rolled back if the reload fails.
 func (pa *prometheusAPI) AddRuleGroups(ctx context.Context, rg *RuleGroup) error {
+	if err := rg.Validate(); err != nil {
+		return err
+	}
+
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
 	if len(pa.py.RuleFiles) == 0 {
 		return fmt.Errorf("rules directory not exists")
 	}
 
-	rd := filepath.Dir(pa.py.RuleFiles[0])
+	rd := filepath.Dir(pa.resolvePath(pa.py.RuleFiles[0]))
 	dst := filepath.Join(rd, rg.Name+".yml")
-	groups := &RuleGroups{
-		Groups: []RuleGroup{*rg},
+	rf, err := readRuleFile(dst)
+	if os.IsNotExist(err) {
+		rf, err = &ruleFile{path: dst}, nil
 	}
-	data, err := yaml.Marshal(groups)
 	if err != nil {
 		return err
 	}
-	if err = os.WriteFile(dst, data, 0644); err != nil {
-		return err
-	}
 
-	return pa.Reload(ctx)
+	return pa.writeRuleFile(ctx, rf, []RuleGroup{*rg})
 }
 
 func (pa *prometheusAPI) GetRules(ctx context.Context) (prometheusv1.RulesResult, error) {
@@ -284,3 +609,7 @@ func (pa *prometheusAPI) GetRules(ctx context.Context) (prometheusv1.RulesResult
 func (pa *prometheusAPI) Alerts(ctx context.Context) (prometheusv1.AlertsResult, error) {
 	return pa.newAPI().Alerts(ctx)
 }
+
+func (pa *prometheusAPI) AlertManagers(ctx context.Context) (prometheusv1.AlertManagersResult, error) {
+	return pa.newAPI().AlertManagers(ctx)
+}
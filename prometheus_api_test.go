@@ -2,11 +2,19 @@ package pag
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
 )
 
 func getPrometheusAPI(t *testing.T) PrometheusAPI {
@@ -22,6 +30,31 @@ func getPrometheusAPI(t *testing.T) PrometheusAPI {
 	return api
 }
 
+// newPrometheusTestAPI returns a PrometheusAPI backed by handler and a
+// temporary copy of testdata/prometheus.yaml.
+func newPrometheusTestAPI(t *testing.T, handler http.Handler) PrometheusAPI {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	data, err := os.ReadFile("testdata/prometheus.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "prometheus.yaml")
+	if err = os.WriteFile(dst, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	api, err := NewPrometheusAPI(srv.Client(), &PrometheusConfig{
+		Endpoint:   srv.URL,
+		ConfigYAML: dst,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
 func TestNewPrometheusAPI(t *testing.T) {
 	api := getPrometheusAPI(t)
 
@@ -41,3 +74,290 @@ func TestPrometheusAPI_Values(t *testing.T) {
 
 	t.Logf("first value = %s", values[0])
 }
+
+func TestPrometheusAPI_UpdateConfigYAML(t *testing.T) {
+	reloadStatus := http.StatusOK
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/-/reload" && reloadStatus != http.StatusOK {
+			http.Error(w, "failed to reload config", reloadStatus)
+		}
+	}))
+	ctx := context.Background()
+	dst := api.(*prometheusAPI).cfg.ConfigYAML
+
+	err := api.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		py.ScrapeConfigs[2].StaticConfig[0].Targets = []string{"127.0.0.1:5300"}
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	data, _ := os.ReadFile(dst)
+	// the alerting section and the comments are not modeled but kept
+	assert.Contains(t, string(data), "# my global config\n")
+	assert.Contains(t, string(data), "alerting:\n  alertmanagers:\n    - static_configs:\n        - targets:\n            - 127.0.0.1:5100\n")
+	assert.Contains(t, string(data), "  - job_name: 'grafana'\n    static_configs:\n      - targets: ['127.0.0.1:5300']\n")
+	assert.Equal(t, []string{"127.0.0.1:5300"}, api.ConfigYAML().ScrapeConfigs[2].StaticConfig[0].Targets)
+
+	reloadStatus = http.StatusInternalServerError
+	err = api.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		py.ScrapeConfigs = nil
+		return nil
+	})
+	assert.Error(t, err)
+	after, _ := os.ReadFile(dst)
+	assert.Equal(t, data, after)
+	assert.Len(t, api.ConfigYAML().ScrapeConfigs, 3)
+}
+
+func TestPrometheusScrapeConfigYAML_HTTPClientConfig(t *testing.T) {
+	data := []byte(`job_name: node
+basic_auth:
+  username: admin
+  password: secret
+follow_redirects: false
+honor_timestamps: false
+`)
+
+	var sc PrometheusScrapeConfigYAML
+	if !assert.NoError(t, yaml.Unmarshal(data, &sc)) {
+		return
+	}
+	assert.Equal(t, "admin", sc.HTTPClientConfig.BasicAuth.Username)
+	assert.False(t, sc.HTTPClientConfig.FollowRedirects)
+	assert.True(t, sc.HTTPClientConfig.EnableHTTP2)
+	if assert.NotNil(t, sc.HonorTimestamps) {
+		assert.False(t, *sc.HonorTimestamps)
+	}
+	assert.Nil(t, sc.EnableCompression)
+
+	out, err := marshalYAML(sc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `basic_auth:
+  password: secret
+  username: admin
+follow_redirects: false
+honor_timestamps: false
+job_name: node
+`, string(out))
+}
+
+func TestPrometheusScrapeConfigYAML_HTTPClientConfigInCode(t *testing.T) {
+	sc := PrometheusScrapeConfigYAML{
+		JobName: "node",
+		HTTPClientConfig: config.HTTPClientConfig{
+			BasicAuth: &config.BasicAuth{Username: "admin", Password: "secret"},
+		},
+	}
+	out, err := marshalYAML(sc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `basic_auth:
+  password: secret
+  username: admin
+job_name: node
+`, string(out))
+
+	sc.HTTPClientConfig = config.DefaultHTTPClientConfig
+	sc.HTTPClientConfig.EnableHTTP2 = false
+	out, err = marshalYAML(sc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "enable_http2: false\njob_name: node\n", string(out))
+
+	out, err = marshalYAML(PrometheusAlertManagerYAML{
+		HTTPClientConfig: config.HTTPClientConfig{BearerToken: "token"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotContains(t, string(out), "follow_redirects")
+	assert.NotContains(t, string(out), "enable_http2")
+	assert.Contains(t, string(out), "bearer_token: token")
+}
+
+func TestPrometheusAPI_StatusEndpoints(t *testing.T) {
+	responses := map[string]string{
+		"/api/v1/series":             `[{"__name__":"up","job":"node"}]`,
+		"/api/v1/labels":             `["__name__","job"]`,
+		"/api/v1/label/job/values":   `["node"]`,
+		"/api/v1/metadata":           `{"up":[{"type":"gauge","help":"Target is up.","unit":""}]}`,
+		"/api/v1/targets/metadata":   `[{"target":{"job":"node"},"metric":"up","type":"gauge","help":"Target is up.","unit":""}]`,
+		"/api/v1/status/tsdb":        `{"headStats":{"numSeries":42,"chunkCount":7,"minTime":1,"maxTime":2},"seriesCountByMetricName":[{"name":"up","value":3}],"labelValueCountByLabelName":[],"memoryInBytesByLabelName":[],"seriesCountByLabelValuePair":[]}`,
+		"/api/v1/status/buildinfo":   `{"version":"2.55.0","revision":"abc","branch":"HEAD","buildUser":"","buildDate":"","goVersion":"go1.23"}`,
+		"/api/v1/status/runtimeinfo": `{"startTime":"2024-01-01T00:00:00Z","CWD":"/prometheus","reloadConfigSuccess":true,"lastConfigTime":"2024-01-01T00:00:00Z","corruptionCount":0,"goroutineCount":10,"GOMAXPROCS":2,"GOGC":"","GODEBUG":"","storageRetention":"15d"}`,
+		"/api/v1/status/flags":       `{"web.enable-admin-api":"false"}`,
+		"/api/v1/status/config":      `{"yaml":"global:\n  scrape_interval: 15s\nscrape_configs:\n- job_name: node\n  honor_timestamps: true\n"}`,
+		"/api/v1/status/walreplay":   `{"min":0,"max":3,"current":3}`,
+		"/api/v1/alertmanagers":      `{"activeAlertmanagers":[{"url":"http://127.0.0.1:5100/api/v2/alerts"}],"droppedAlertmanagers":[]}`,
+	}
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":` + data + `}`))
+	}))
+	ctx := context.Background()
+
+	series, _, err := api.Series(ctx, []string{"up"}, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, model.LabelValue("node"), series[0]["job"])
+
+	names, _, err := api.LabelNames(ctx, nil, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"__name__", "job"}, names)
+
+	values, _, err := api.LabelValues(ctx, "job", nil, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, model.LabelValues{"node"}, values)
+
+	metadata, err := api.Metadata(ctx, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Target is up.", metadata["up"][0].Help)
+
+	targetsMetadata, err := api.TargetsMetadata(ctx, `{job="node"}`, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "up", targetsMetadata[0].Metric)
+
+	tsdb, err := api.TSDB(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, tsdb.HeadStats.NumSeries)
+	assert.Equal(t, uint64(3), tsdb.SeriesCountByMetricName[0].Value)
+
+	build, err := api.Buildinfo(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.55.0", build.Version)
+
+	runtime, err := api.Runtimeinfo(ctx)
+	assert.NoError(t, err)
+	assert.True(t, runtime.ReloadConfigSuccess)
+
+	flags, err := api.Flags(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "false", flags["web.enable-admin-api"])
+
+	loaded, err := api.LoadedConfig(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "node", loaded.ScrapeConfigs[0].JobName)
+		assert.Equal(t, "15s", loaded.Global.ScrapeInterval.String())
+	}
+
+	replay, err := api.WalReplay(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, replay.Current)
+
+	ams, err := api.AlertManagers(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, ams.Active, 1)
+}
+
+func TestPrometheusAPI_ReloadAndVerify(t *testing.T) {
+	reloadVerifyInterval = 10 * time.Millisecond
+	t.Cleanup(func() { reloadVerifyInterval = 500 * time.Millisecond })
+
+	var (
+		mu         sync.Mutex
+		lastReload = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		success    = true
+		applies    = true
+	)
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/-/reload":
+			if applies {
+				lastReload = lastReload.Add(time.Second)
+			}
+		case "/api/v1/status/runtimeinfo":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"lastConfigTime":"` + lastReload.Format(time.RFC3339) +
+				`","reloadConfigSuccess":` + map[bool]string{true: "true", false: "false"}[success] + `}}`))
+		case "/api/v1/status/config":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"yaml":"global: {}"}}`))
+		}
+	}))
+	ctx := context.Background()
+
+	assert.NoError(t, api.ReloadAndVerify(ctx, time.Second))
+
+	// Prometheus accepted the reload but kept running the old configuration
+	mu.Lock()
+	applies = false
+	mu.Unlock()
+	err := api.ReloadAndVerify(ctx, 50*time.Millisecond)
+	var notApplied *ReloadNotAppliedError
+	if assert.True(t, errors.As(err, &notApplied)) {
+		assert.Equal(t, "prometheus", notApplied.Service)
+		assert.Contains(t, notApplied.Reason, "2024-01-01T00:00:01Z")
+	}
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// prometheus_config_last_reload_successful is 0
+	mu.Lock()
+	success = false
+	mu.Unlock()
+	err = api.ReloadAndVerify(ctx, time.Second)
+	if assert.True(t, errors.As(err, &notApplied)) {
+		assert.EqualError(t, err, "prometheus reload not applied: last configuration reload was unsuccessful")
+	}
+}
+
+func TestPrometheusAPI_LifecycleErrors(t *testing.T) {
+	status := map[string]int{}
+	message := map[string]string{}
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code, ok := status[r.URL.Path]; ok {
+			http.Error(w, message[r.URL.Path], code)
+		}
+	}))
+	ctx := context.Background()
+
+	assert.NoError(t, api.Healthy(ctx))
+	assert.NoError(t, api.Ready(ctx))
+	assert.NoError(t, api.Reload(ctx))
+
+	status["/-/ready"], message["/-/ready"] = http.StatusServiceUnavailable, "Service Unavailable"
+	err := api.Ready(ctx)
+	var notReady *NotReadyError
+	if assert.True(t, errors.As(err, &notReady)) {
+		assert.Equal(t, http.StatusServiceUnavailable, notReady.StatusCode)
+	}
+	var statusErr *StatusError
+	assert.True(t, errors.As(err, &statusErr))
+
+	status["/-/healthy"] = http.StatusInternalServerError
+	var unhealthy *UnhealthyError
+	assert.True(t, errors.As(api.Healthy(ctx), &unhealthy))
+
+	status["/-/reload"], message["/-/reload"] = http.StatusInternalServerError, "failed to reload config: bad rule file"
+	err = api.Reload(ctx)
+	var reloadFailed *ReloadFailedError
+	if assert.True(t, errors.As(err, &reloadFailed)) {
+		assert.Equal(t, "failed to reload config: bad rule file", reloadFailed.Message)
+	}
+	assert.EqualError(t, err, "prometheus POST /-/reload: 500 Internal Server Error: failed to reload config: bad rule file")
+
+	// a failed reload rolls the change back and keeps the typed error
+	err = api.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		py.RuleFiles = nil
+		return nil
+	})
+	assert.True(t, errors.As(err, &reloadFailed))
+	assert.NotEmpty(t, api.ConfigYAML().RuleFiles)
+
+	status["/-/reload"], message["/-/reload"] = http.StatusForbidden, "Lifecycle API is not enabled."
+	assert.True(t, errors.As(api.Reload(ctx), &reloadFailed))
+
+	status["/-/reload"], message["/-/reload"] = http.StatusUnauthorized, "Unauthorized"
+	var unauthorized *UnauthorizedError
+	assert.True(t, errors.As(api.Reload(ctx), &unauthorized))
+	assert.False(t, errors.As(api.Reload(ctx), &reloadFailed))
+}
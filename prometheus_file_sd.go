@@ -0,0 +1,310 @@
+package pag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// resolvePath resolves a path of the Prometheus configuration, relative paths
+// are relative to the directory of the configuration file.
+func (pa *prometheusAPI) resolvePath(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(pa.cfg.ConfigYAML), p)
+}
+
+// fileSDConfigs returns the file_sd_configs of the job, or of the first job
+// using file_sd_configs when jobName is empty.
+func (pa *prometheusAPI) fileSDConfigs(jobName string) (string, []FileSDConfig, error) {
+	for _, sc := range pa.py.ScrapeConfigs {
+		if jobName != "" && sc.JobName != jobName {
+			continue
+		}
+		if len(sc.FileSDConfigs) != 0 && len(sc.FileSDConfigs[0].Files) != 0 {
+			return sc.JobName, sc.FileSDConfigs, nil
+		}
+		if jobName != "" {
+			return "", nil, fmt.Errorf("scrape config %q has no file_sd_configs", jobName)
+		}
+	}
+
+	if jobName != "" {
+		return "", nil, fmt.Errorf("scrape config %q: %w", jobName, ErrNotFound)
+	}
+	return "", nil, fmt.Errorf("no file_sd_configs configured")
+}
+
+// targetGroupFile returns the file of the named target group, named after the
+// first file pattern of the job it matches, see groupFileName. A group that
+// fits none of the patterns is an error, Prometheus would never read its file.
+func (pa *prometheusAPI) targetGroupFile(jobName, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || hasGlobMeta(name) {
+		return "", fmt.Errorf("bad target group name %q", name)
+	}
+
+	job, configs, err := pa.fileSDConfigs(jobName)
+	if err != nil {
+		return "", err
+	}
+
+	for _, fc := range configs {
+		for _, pattern := range fc.Files {
+			pattern = filepath.Clean(pa.resolvePath(pattern))
+			dir := filepath.Dir(pattern)
+			if hasGlobMeta(dir) {
+				continue
+			}
+			file := filepath.Join(dir, groupFileName(filepath.Base(pattern), name))
+			if ok, _ := filepath.Match(pattern, file); ok {
+				return file, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("target group %q matches no file_sd_configs file of scrape config %q", name, job)
+}
+
+// hasGlobMeta reports whether s has characters filepath.Match treats specially.
+func hasGlobMeta(s string) bool {
+	magic := `*?[\`
+	if runtime.GOOS == "windows" {
+		magic = `*?[`
+	}
+	return strings.ContainsAny(s, magic)
+}
+
+// groupFileName returns the base name of the file of the named group for a
+// file pattern: the pattern with the name in place of its "*", e.g. node-web.yml
+// for node-*.yml, or the name with the extension of the pattern.
+func groupFileName(pattern, name string) string {
+	if prefix, suffix, ok := strings.Cut(pattern, "*"); ok && !hasGlobMeta(prefix) && !hasGlobMeta(suffix) {
+		return prefix + name + suffix
+	}
+	return name + filepath.Ext(pattern)
+}
+
+// targetGroupName is the reverse of groupFileName for a file matching pattern.
+func targetGroupName(pattern, file string) string {
+	base := filepath.Base(file)
+	if prefix, suffix, ok := strings.Cut(filepath.Base(pattern), "*"); ok && !hasGlobMeta(prefix) && !hasGlobMeta(suffix) {
+		return strings.TrimSuffix(strings.TrimPrefix(base, prefix), suffix)
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func readTargetGroup(src string) ([]ServiceDiscoveryEndpoint, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []ServiceDiscoveryEndpoint
+	if err = yaml.Unmarshal(data, &endpoints); err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+	return endpoints, nil
+}
+
+func writeTargetGroup(dst string, endpoints []ServiceDiscoveryEndpoint) error {
+	var data []byte
+	var err error
+	if filepath.Ext(dst) == ".json" {
+		data, err = json.MarshalIndent(endpoints, "", "  ")
+	} else {
+		data, err = yaml.Marshal(endpoints)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(dst, data, 0644)
+}
+
+func (pa *prometheusAPI) ListTargetGroups(jobName string) ([]ServiceDiscovery, error) {
+	pa.mu.RLock()
+	defer pa.mu.RUnlock()
+
+	job, configs, err := pa.fileSDConfigs(jobName)
+	if err != nil {
+		return nil, err
+	}
+
+	// the pattern each file was found with, to name its group
+	patterns := map[string]string{}
+	var files []string
+	for _, fc := range configs {
+		for _, pattern := range fc.Files {
+			pattern = filepath.Clean(pa.resolvePath(pattern))
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, err
+			}
+			for _, match := range matches {
+				if _, ok := patterns[match]; !ok {
+					patterns[match] = pattern
+					files = append(files, match)
+				}
+			}
+		}
+	}
+	sort.Strings(files)
+
+	out := make([]ServiceDiscovery, 0, len(files))
+	for _, file := range files {
+		endpoints, err := readTargetGroup(file)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ServiceDiscovery{
+			Name:      targetGroupName(patterns[file], file),
+			Job:       job,
+			Endpoints: endpoints,
+		})
+	}
+
+	return out, nil
+}
+
+func (pa *prometheusAPI) GetTargetGroup(jobName, name string) (*ServiceDiscovery, error) {
+	groups, err := pa.ListTargetGroups(jobName)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range groups {
+		if groups[i].Name == name {
+			return &groups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("target group %q: %w", name, ErrNotFound)
+}
+
+// updateTargetGroup applies fn to the endpoints of the group file and writes
+// them back, the file is created when missing and create is set.
+func (pa *prometheusAPI) updateTargetGroup(jobName, name string, create bool, fn func([]ServiceDiscoveryEndpoint) ([]ServiceDiscoveryEndpoint, error)) error {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	dst, err := pa.targetGroupFile(jobName, name)
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := readTargetGroup(dst)
+	switch {
+	case os.IsNotExist(err) && create:
+	case os.IsNotExist(err):
+		return fmt.Errorf("target group %q: %w", name, ErrNotFound)
+	case err != nil:
+		return err
+	}
+
+	if endpoints, err = fn(endpoints); err != nil {
+		return err
+	}
+
+	return writeTargetGroup(dst, endpoints)
+}
+
+func labelsEqual(a, b map[string]string) bool {
+	return len(a) == len(b) && (len(a) == 0 || reflect.DeepEqual(a, b))
+}
+
+func addTargets(endpoints []ServiceDiscoveryEndpoint, labels map[string]string, targets []string) []ServiceDiscoveryEndpoint {
+	i := -1
+	for j := range endpoints {
+		if labelsEqual(endpoints[j].Labels, labels) {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		endpoints = append(endpoints, ServiceDiscoveryEndpoint{Labels: labels})
+		i = len(endpoints) - 1
+	}
+
+	for _, target := range targets {
+		var exists bool
+		for _, t := range endpoints[i].Targets {
+			exists = exists || t == target
+		}
+		if !exists {
+			endpoints[i].Targets = append(endpoints[i].Targets, target)
+		}
+	}
+	return endpoints
+}
+
+// removeTargets removes targets from endpoints and reports how many were removed.
+func removeTargets(endpoints []ServiceDiscoveryEndpoint, targets []string) ([]ServiceDiscoveryEndpoint, int) {
+	remove := map[string]bool{}
+	for _, target := range targets {
+		remove[target] = true
+	}
+
+	var removed int
+	out := endpoints[:0]
+	for _, ep := range endpoints {
+		kept := ep.Targets[:0]
+		for _, t := range ep.Targets {
+			if remove[t] {
+				removed++
+				continue
+			}
+			kept = append(kept, t)
+		}
+		if len(kept) != 0 {
+			ep.Targets = kept
+			out = append(out, ep)
+		}
+	}
+	return out, removed
+}
+
+func (pa *prometheusAPI) AddTargets(ctx context.Context, jobName, name string, labels map[string]string, targets ...string) error {
+	return pa.updateTargetGroup(jobName, name, true, func(endpoints []ServiceDiscoveryEndpoint) ([]ServiceDiscoveryEndpoint, error) {
+		return addTargets(endpoints, labels, targets), nil
+	})
+}
+
+func (pa *prometheusAPI) RemoveTargets(ctx context.Context, jobName, name string, targets ...string) error {
+	return pa.updateTargetGroup(jobName, name, false, func(endpoints []ServiceDiscoveryEndpoint) ([]ServiceDiscoveryEndpoint, error) {
+		endpoints, _ = removeTargets(endpoints, targets)
+		return endpoints, nil
+	})
+}
+
+func (pa *prometheusAPI) SetTargetLabels(ctx context.Context, jobName, name, target string, labels map[string]string) error {
+	return pa.updateTargetGroup(jobName, name, false, func(endpoints []ServiceDiscoveryEndpoint) ([]ServiceDiscoveryEndpoint, error) {
+		endpoints, removed := removeTargets(endpoints, []string{target})
+		if removed == 0 {
+			return nil, fmt.Errorf("target %q: %w", target, ErrNotFound)
+		}
+		return addTargets(endpoints, labels, []string{target}), nil
+	})
+}
+
+func (pa *prometheusAPI) DeleteTargetGroup(ctx context.Context, jobName, name string) error {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	dst, err := pa.targetGroupFile(jobName, name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(dst)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("target group %q: %w", name, ErrNotFound)
+	}
+	return err
+}
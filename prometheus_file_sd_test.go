@@ -0,0 +1,131 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusAPI_TargetGroups(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "targets"), 0755))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "json"), 0755))
+	dst := filepath.Join(dir, "prometheus.yaml")
+	assert.NoError(t, os.WriteFile(dst, []byte(`scrape_configs:
+  - job_name: static
+    static_configs:
+      - targets: ['127.0.0.1:9090']
+  - job_name: json
+    file_sd_configs:
+      - files: ['json/*.json']
+  - job_name: node
+    file_sd_configs:
+      - files: ['targets/*.yaml']
+`), 0644))
+
+	api, err := NewPrometheusAPI(srv.Client(), &PrometheusConfig{Endpoint: srv.URL, ConfigYAML: dst})
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := context.Background()
+
+	// the first file_sd job is used without a job name
+	assert.NoError(t, api.AddTarget(ctx, &ServiceDiscovery{
+		Name:      "legacy",
+		Endpoints: []ServiceDiscoveryEndpoint{{Targets: []string{"127.0.0.1:1"}}},
+	}))
+	assert.FileExists(t, filepath.Join(dir, "json", "legacy.json"))
+
+	assert.NoError(t, api.AddTarget(ctx, &ServiceDiscovery{
+		Name:      "web",
+		Job:       "node",
+		Endpoints: []ServiceDiscoveryEndpoint{{Targets: []string{"10.0.0.1:9100"}, Labels: map[string]string{"env": "prod"}}},
+	}))
+	assert.NoError(t, api.AddTargets(ctx, "node", "web", map[string]string{"env": "prod"}, "10.0.0.2:9100", "10.0.0.1:9100"))
+	assert.NoError(t, api.AddTargets(ctx, "node", "db", nil, "10.0.1.1:9100"))
+
+	groups, err := api.ListTargetGroups("node")
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, groups, 2) {
+		return
+	}
+	assert.Equal(t, "db", groups[0].Name)
+	assert.Equal(t, "node", groups[0].Job)
+	assert.Equal(t, []string{"10.0.0.1:9100", "10.0.0.2:9100"}, groups[1].Endpoints[0].Targets)
+
+	assert.NoError(t, api.SetTargetLabels(ctx, "node", "web", "10.0.0.2:9100", map[string]string{"env": "staging"}))
+	group, err := api.GetTargetGroup("node", "web")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []ServiceDiscoveryEndpoint{
+		{Targets: []string{"10.0.0.1:9100"}, Labels: map[string]string{"env": "prod"}},
+		{Targets: []string{"10.0.0.2:9100"}, Labels: map[string]string{"env": "staging"}},
+	}, group.Endpoints)
+	assert.True(t, errors.Is(api.SetTargetLabels(ctx, "node", "web", "10.0.0.9:9100", nil), ErrNotFound))
+
+	assert.NoError(t, api.RemoveTargets(ctx, "node", "web", "10.0.0.1:9100"))
+	group, _ = api.GetTargetGroup("node", "web")
+	assert.Len(t, group.Endpoints, 1)
+	assert.True(t, errors.Is(api.RemoveTargets(ctx, "node", "missing", "10.0.0.1:9100"), ErrNotFound))
+
+	assert.NoError(t, api.DeleteTargetGroup(ctx, "node", "web"))
+	_, err = api.GetTargetGroup("node", "web")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(api.DeleteTargetGroup(ctx, "node", "web"), ErrNotFound))
+
+	_, err = api.ListTargetGroups("static")
+	assert.Error(t, err)
+	_, err = api.ListTargetGroups("missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Error(t, api.AddTargets(ctx, "node", "../escape", nil, "10.0.0.1:9100"))
+}
+
+func TestPrometheusAPI_TargetGroupFilePattern(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sd"), 0755))
+	dst := filepath.Join(dir, "prometheus.yaml")
+	assert.NoError(t, os.WriteFile(dst, []byte(`scrape_configs:
+  - job_name: node
+    file_sd_configs:
+      - files: ['sd/node-*.yml']
+  - job_name: fixed
+    file_sd_configs:
+      - files: ['sd/fixed.yml']
+`), 0644))
+
+	api, err := NewPrometheusAPI(srv.Client(), &PrometheusConfig{Endpoint: srv.URL, ConfigYAML: dst})
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := context.Background()
+
+	// the group is written where the pattern of the job picks it up
+	assert.NoError(t, api.AddTargets(ctx, "node", "web", nil, "10.0.0.1:9100"))
+	assert.FileExists(t, filepath.Join(dir, "sd", "node-web.yml"))
+	assert.NoFileExists(t, filepath.Join(dir, "sd", "web.yml"))
+
+	group, err := api.GetTargetGroup("node", "web")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"10.0.0.1:9100"}, group.Endpoints[0].Targets)
+	}
+
+	// a literal file only holds the group named after it
+	assert.NoError(t, api.AddTargets(ctx, "fixed", "fixed", nil, "10.0.0.2:9100"))
+	assert.Error(t, api.AddTargets(ctx, "fixed", "web", nil, "10.0.0.3:9100"))
+	assert.NoFileExists(t, filepath.Join(dir, "sd", "web.yml"))
+}
@@ -0,0 +1,217 @@
+package pag
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+)
+
+type RelabelAction string
+
+const (
+	RelabelReplace   RelabelAction = "replace"
+	RelabelKeep      RelabelAction = "keep"
+	RelabelDrop      RelabelAction = "drop"
+	RelabelKeepEqual RelabelAction = "keepequal"
+	RelabelDropEqual RelabelAction = "dropequal"
+	RelabelHashMod   RelabelAction = "hashmod"
+	RelabelLabelMap  RelabelAction = "labelmap"
+	RelabelLabelDrop RelabelAction = "labeldrop"
+	RelabelLabelKeep RelabelAction = "labelkeep"
+	RelabelLowercase RelabelAction = "lowercase"
+	RelabelUppercase RelabelAction = "uppercase"
+)
+
+// RelabelConfigYAML is a relabeling step, Prometheus fills in the defaults of
+// the fields left unset: separator ";", regex "(.*)" and replacement "$1" when
+// nil and action "replace" when empty.
+type RelabelConfigYAML struct {
+	// A list of labels from which values are taken and concatenated
+	// with the configured separator in order.
+	SourceLabels []string `json:"source_labels,omitempty"`
+	// Separator is the string between concatenated values from the source labels.
+	Separator *string `json:"separator,omitempty"`
+	// Regex against which the concatenation is matched.
+	Regex *string `json:"regex,omitempty"`
+	// Modulus to take of the hash of concatenated values from the source labels.
+	Modulus uint64 `json:"modulus,omitempty"`
+	// TargetLabel is the label to which the resulting string is written in a replacement.
+	TargetLabel string `json:"target_label,omitempty"`
+	// Replacement is the regex replacement pattern to be used.
+	Replacement *string `json:"replacement,omitempty"`
+	// Action is the action to be performed for the relabeling.
+	Action RelabelAction `json:"action,omitempty"`
+}
+
+// config returns the relabel.Config Prometheus loads for c, with the defaults
+// filled in, and validates it.
+func (c *RelabelConfigYAML) config() (*relabel.Config, error) {
+	out := relabel.DefaultRelabelConfig
+	if c.SourceLabels != nil {
+		out.SourceLabels = make(model.LabelNames, 0, len(c.SourceLabels))
+		for _, name := range c.SourceLabels {
+			out.SourceLabels = append(out.SourceLabels, model.LabelName(name))
+		}
+	}
+	if c.Separator != nil {
+		out.Separator = *c.Separator
+	}
+	if c.Regex != nil {
+		re, err := relabel.NewRegexp(*c.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", *c.Regex, err)
+		}
+		out.Regex = re
+	}
+	out.Modulus = c.Modulus
+	out.TargetLabel = c.TargetLabel
+	if c.Replacement != nil {
+		out.Replacement = *c.Replacement
+	}
+	if c.Action != "" {
+		out.Action = relabel.Action(strings.ToLower(string(c.Action)))
+	}
+
+	switch out.Action {
+	case relabel.Replace, relabel.Keep, relabel.Drop, relabel.KeepEqual, relabel.DropEqual, relabel.HashMod,
+		relabel.LabelMap, relabel.LabelDrop, relabel.LabelKeep, relabel.Lowercase, relabel.Uppercase:
+	default:
+		return nil, fmt.Errorf("unknown relabel action %q", c.Action)
+	}
+	if err := out.Validate(); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Validate checks c the way Prometheus does when loading it.
+func (c *RelabelConfigYAML) Validate() error {
+	_, err := c.config()
+	return err
+}
+
+func relabelConfigs(cfgs []RelabelConfigYAML) ([]*relabel.Config, error) {
+	out := make([]*relabel.Config, 0, len(cfgs))
+	for i := range cfgs {
+		rc, err := cfgs[i].config()
+		if err != nil {
+			return nil, fmt.Errorf("relabel config %d: %w", i, err)
+		}
+		out = append(out, rc)
+	}
+	return out, nil
+}
+
+// Relabel applies cfgs to lset in order, the way Prometheus applies
+// metric_relabel_configs to a scraped series or write_relabel_configs to a
+// series sent to remote storage. keep is false if the series is dropped.
+func Relabel(lset map[string]string, cfgs ...RelabelConfigYAML) (out map[string]string, keep bool, err error) {
+	rcs, err := relabelConfigs(cfgs)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ls, keep := relabel.Process(labels.FromMap(lset), rcs...)
+	if !keep {
+		return nil, false, nil
+	}
+	return ls.Map(), true, nil
+}
+
+// RelabelTarget previews how a target discovered with the given labels is
+// labeled by the scrape config: the job, scheme, metrics path, scrape interval
+// and timeout and params labels are set, relabel_configs are applied, the port
+// implied by the scheme is added to the address and instance defaults to the
+// address. The returned labels are the ones the scraped series carry, without
+// the internal "__" labels. keep is false if the target is dropped.
+//
+// The global scrape interval and timeout are not known to sc, the Prometheus
+// defaults of 1m and 10s are used when sc does not set them.
+func (sc *PrometheusScrapeConfigYAML) RelabelTarget(discovered map[string]string) (out map[string]string, keep bool, err error) {
+	rcs, err := relabelConfigs(sc.RelabelConfigs)
+	if err != nil {
+		return nil, false, err
+	}
+
+	scrapeInterval, scrapeTimeout := sc.ScrapeInterval, sc.ScrapeTimeout
+	if scrapeInterval == 0 {
+		scrapeInterval = model.Duration(time.Minute)
+	}
+	if scrapeTimeout == 0 {
+		scrapeTimeout = model.Duration(10 * time.Second)
+		if scrapeTimeout > scrapeInterval {
+			scrapeTimeout = scrapeInterval
+		}
+	}
+	metricsPath, scheme := sc.MetricsPath, sc.Scheme
+	if metricsPath == "" {
+		metricsPath = "/metrics"
+	}
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	lb := labels.NewBuilder(labels.FromMap(discovered))
+	for name, value := range map[string]string{
+		model.JobLabel:            sc.JobName,
+		model.ScrapeIntervalLabel: scrapeInterval.String(),
+		model.ScrapeTimeoutLabel:  scrapeTimeout.String(),
+		model.MetricsPathLabel:    metricsPath,
+		model.SchemeLabel:         scheme,
+	} {
+		if lb.Get(name) == "" {
+			lb.Set(name, value)
+		}
+	}
+	for name, values := range sc.Params {
+		if len(values) != 0 && lb.Get(model.ParamLabelPrefix+name) == "" {
+			lb.Set(model.ParamLabelPrefix+name, values[0])
+		}
+	}
+
+	if !relabel.ProcessBuilder(lb, rcs...) {
+		return nil, false, nil
+	}
+
+	addr := lb.Get(model.AddressLabel)
+	if addr == "" {
+		return nil, false, errors.New("no address")
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		if _, _, err := net.SplitHostPort(addr + ":1234"); err == nil {
+			switch lb.Get(model.SchemeLabel) {
+			case "http", "":
+				addr += ":80"
+			case "https":
+				addr += ":443"
+			default:
+				return nil, false, fmt.Errorf("invalid scheme: %q", lb.Get(model.SchemeLabel))
+			}
+		}
+	}
+	if strings.Contains(addr, "/") {
+		return nil, false, fmt.Errorf("%q is not a valid hostname", addr)
+	}
+	if lb.Get(model.InstanceLabel) == "" {
+		lb.Set(model.InstanceLabel, addr)
+	}
+
+	out = map[string]string{}
+	lb.Range(func(l labels.Label) {
+		if !strings.HasPrefix(l.Name, model.ReservedLabelPrefix) {
+			out[l.Name] = l.Value
+		}
+	})
+	for name, value := range out {
+		if !model.LabelValue(value).IsValid() {
+			return nil, false, fmt.Errorf("invalid label value for %q: %q", name, value)
+		}
+	}
+	return out, true, nil
+}
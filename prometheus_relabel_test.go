@@ -0,0 +1,139 @@
+package pag
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPrometheusScrapeConfigYAML_RelabelTarget(t *testing.T) {
+	dev, host := "dev", "([^:]+)(?::\\d+)?"
+	sc := &PrometheusScrapeConfigYAML{
+		JobName: "node",
+		Scheme:  "https",
+		RelabelConfigs: []RelabelConfigYAML{
+			{SourceLabels: []string{"__meta_env"}, Regex: &dev, Action: RelabelDrop},
+			{SourceLabels: []string{"__meta_env"}, TargetLabel: "env"},
+			{SourceLabels: []string{"__address__"}, Regex: &host, TargetLabel: "host"},
+		},
+	}
+
+	got, keep, err := sc.RelabelTarget(map[string]string{"__address__": "db1", "__meta_env": "prod", "team": "dba"})
+	assert.NoError(t, err)
+	assert.True(t, keep)
+	assert.Equal(t, map[string]string{
+		"job":      "node",
+		"instance": "db1:443",
+		"env":      "prod",
+		"host":     "db1",
+		"team":     "dba",
+	}, got)
+
+	got, keep, err = sc.RelabelTarget(map[string]string{"__address__": "db2:9100", "__meta_env": "dev"})
+	assert.NoError(t, err)
+	assert.False(t, keep)
+	assert.Nil(t, got)
+
+	_, _, err = sc.RelabelTarget(map[string]string{"__meta_env": "prod"})
+	assert.Error(t, err)
+
+	sc.RelabelConfigs = []RelabelConfigYAML{{Action: RelabelHashMod, TargetLabel: "shard"}}
+	_, _, err = sc.RelabelTarget(map[string]string{"__address__": "db1"})
+	assert.Error(t, err)
+
+	// a discovered __param_ label wins over params, like __scheme__ over scheme
+	sc.Params = map[string][]string{"module": {"http_2xx"}}
+	sc.RelabelConfigs = []RelabelConfigYAML{{SourceLabels: []string{"__param_module"}, TargetLabel: "module"}}
+	got, _, err = sc.RelabelTarget(map[string]string{"__address__": "db1", "__param_module": "tcp_connect"})
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp_connect", got["module"])
+	got, _, err = sc.RelabelTarget(map[string]string{"__address__": "db1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "http_2xx", got["module"])
+}
+
+func TestRelabel(t *testing.T) {
+	goMetrics, hash, invalid := "go_.*", "pod_template_hash", "("
+	cfgs := []RelabelConfigYAML{
+		{SourceLabels: []string{"__name__"}, Regex: &goMetrics, Action: RelabelDrop},
+		{Regex: &hash, Action: RelabelLabelDrop},
+		{SourceLabels: []string{"namespace"}, TargetLabel: "namespace", Action: RelabelUppercase},
+	}
+
+	got, keep, err := Relabel(map[string]string{"__name__": "up", "namespace": "kube", "pod_template_hash": "abc"}, cfgs...)
+	assert.NoError(t, err)
+	assert.True(t, keep)
+	assert.Equal(t, map[string]string{"__name__": "up", "namespace": "KUBE"}, got)
+
+	_, keep, err = Relabel(map[string]string{"__name__": "go_goroutines"}, cfgs...)
+	assert.NoError(t, err)
+	assert.False(t, keep)
+
+	_, _, err = Relabel(nil, RelabelConfigYAML{Action: "rewrite"})
+	assert.Error(t, err)
+	_, _, err = Relabel(nil, RelabelConfigYAML{Regex: &invalid, TargetLabel: "x"})
+	assert.Error(t, err)
+}
+
+func TestRelabel_EmptyReplacementSeparatorAndRegex(t *testing.T) {
+	var cfgs []RelabelConfigYAML
+	err := yaml.Unmarshal([]byte(`
+- source_labels: [a, c]
+  separator: ""
+  target_label: joined
+- source_labels: [a]
+  target_label: b
+  replacement: ""
+- source_labels: [c]
+  regex: ""
+  action: drop
+`), &cfgs)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// an empty replacement removes the target label, as in Prometheus
+	got, keep, err := Relabel(map[string]string{"a": "x", "b": "y", "c": "z"}, cfgs...)
+	assert.NoError(t, err)
+	assert.True(t, keep)
+	assert.Equal(t, map[string]string{"a": "x", "c": "z", "joined": "xz"}, got)
+
+	out, err := yaml.Marshal(cfgs)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(out), "separator: \"\"")
+		assert.Contains(t, string(out), "replacement: \"\"")
+		assert.Contains(t, string(out), "regex: \"\"")
+	}
+
+	// an empty regex only matches an empty value, the target without c is dropped
+	_, keep, err = Relabel(map[string]string{"a": "x"}, cfgs...)
+	assert.NoError(t, err)
+	assert.False(t, keep)
+
+	// unset fields take the defaults
+	got, _, err = Relabel(map[string]string{"a": "x", "c": "z"},
+		RelabelConfigYAML{SourceLabels: []string{"a", "c"}, TargetLabel: "joined"})
+	assert.NoError(t, err)
+	assert.Equal(t, "x;z", got["joined"])
+}
+
+func TestPrometheusAPI_ScrapeConfigRelabelValidation(t *testing.T) {
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	sc := &PrometheusScrapeConfigYAML{
+		JobName:              "node",
+		MetricRelabelConfigs: []RelabelConfigYAML{{Action: RelabelLabelKeep, SourceLabels: []string{"job"}}},
+	}
+	assert.Error(t, api.AddScrapeConfig(context.Background(), sc))
+
+	keep := "job|instance"
+	sc.MetricRelabelConfigs = []RelabelConfigYAML{{Action: RelabelLabelKeep, Regex: &keep}}
+	assert.NoError(t, api.AddScrapeConfig(context.Background(), sc))
+	got, _ := api.GetScrapeConfig("node")
+	if assert.NotNil(t, got.MetricRelabelConfigs[0].Regex) {
+		assert.Equal(t, "job|instance", *got.MetricRelabelConfigs[0].Regex)
+	}
+}
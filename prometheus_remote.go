@@ -0,0 +1,293 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	urlpkg "net/url"
+
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+)
+
+type PrometheusRemoteWriteYAML struct {
+	// The URL of the endpoint to send samples to.
+	URL string `json:"url"`
+	// Timeout for requests to the remote write endpoint.
+	RemoteTimeout model.Duration `json:"remote_timeout,omitempty"`
+	// Custom HTTP headers to be sent along with each remote write request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// List of remote write relabel configurations.
+	WriteRelabelConfigs []RelabelConfigYAML `json:"write_relabel_configs,omitempty"`
+	// Name of the remote write config, which if specified must be unique among remote write configs.
+	Name string `json:"name,omitempty"`
+	// Enables sending of exemplars over remote write.
+	SendExemplars bool `json:"send_exemplars,omitempty"`
+	// Enables sending of native histograms over remote write.
+	SendNativeHistograms bool `json:"send_native_histograms,omitempty"`
+	// The protobuf message to use when writing to the remote write endpoint.
+	ProtobufMessage string `json:"protobuf_message,omitempty"`
+
+	HTTPClientConfig config.HTTPClientConfig `json:"-"`
+
+	// Configures the queue used to write to remote storage.
+	QueueConfig *RemoteWriteQueueConfigYAML `json:"queue_config,omitempty"`
+	// Configures the sending of series metadata to remote storage.
+	MetadataConfig *RemoteWriteMetadataConfigYAML `json:"metadata_config,omitempty"`
+	// Configures AWS's Signature Verification 4 signing process to sign requests.
+	SigV4 *SigV4YAML `json:"sigv4,omitempty"`
+}
+
+func (c PrometheusRemoteWriteYAML) MarshalJSON() ([]byte, error) {
+	type plain PrometheusRemoteWriteYAML
+	return marshalWithHTTPClientConfig(plain(c), c.HTTPClientConfig)
+}
+
+func (c *PrometheusRemoteWriteYAML) UnmarshalJSON(data []byte) error {
+	type plain PrometheusRemoteWriteYAML
+	return unmarshalWithHTTPClientConfig(data, (*plain)(c), &c.HTTPClientConfig)
+}
+
+// id is the name of the config, or its URL when it has none.
+func (c *PrometheusRemoteWriteYAML) id() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.URL
+}
+
+type RemoteWriteQueueConfigYAML struct {
+	// Number of samples to buffer per shard before we block reading of more
+	// samples from the WAL.
+	Capacity int `json:"capacity,omitempty"`
+	// Maximum number of shards, i.e. amount of concurrency.
+	MaxShards int `json:"max_shards,omitempty"`
+	// Minimum number of shards, i.e. amount of concurrency.
+	MinShards int `json:"min_shards,omitempty"`
+	// Maximum number of samples per send.
+	MaxSamplesPerSend int `json:"max_samples_per_send,omitempty"`
+	// Maximum time a sample will wait in buffer.
+	BatchSendDeadline model.Duration `json:"batch_send_deadline,omitempty"`
+	// Initial retry delay. Gets doubled for every retry.
+	MinBackoff model.Duration `json:"min_backoff,omitempty"`
+	// Maximum retry delay.
+	MaxBackoff model.Duration `json:"max_backoff,omitempty"`
+	// Retry upon receiving a 429 status code from the remote-write storage.
+	RetryOnRateLimit bool `json:"retry_on_http_429,omitempty"`
+	// Samples older than the limit will be dropped.
+	SampleAgeLimit model.Duration `json:"sample_age_limit,omitempty"`
+}
+
+type RemoteWriteMetadataConfigYAML struct {
+	// Whether metric metadata is sent to remote storage or not, true when nil.
+	Send *bool `json:"send,omitempty"`
+	// How frequently metric metadata is sent to remote storage.
+	SendInterval model.Duration `json:"send_interval,omitempty"`
+	// Maximum number of samples per send.
+	MaxSamplesPerSend int `json:"max_samples_per_send,omitempty"`
+}
+
+type PrometheusRemoteReadYAML struct {
+	// The URL of the endpoint to query from.
+	URL string `json:"url"`
+	// Timeout for requests to the remote read endpoint.
+	RemoteTimeout model.Duration `json:"remote_timeout,omitempty"`
+	// The maximum size of any single frame of a chunked read response.
+	ChunkedReadLimit uint64 `json:"chunked_read_limit,omitempty"`
+	// Custom HTTP headers to be sent along with each remote read request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Whether reads should be made for queries for time ranges that
+	// the local storage should have complete data for.
+	ReadRecent bool `json:"read_recent,omitempty"`
+	// Name of the remote read config, which if specified must be unique among remote read configs.
+	Name string `json:"name,omitempty"`
+
+	HTTPClientConfig config.HTTPClientConfig `json:"-"`
+
+	// An optional list of equality matchers which have to be present
+	// in a selector to query the remote read endpoint.
+	RequiredMatchers map[string]string `json:"required_matchers,omitempty"`
+	// Whether to use the external labels as selectors for the remote read endpoint, true when nil.
+	FilterExternalLabels *bool `json:"filter_external_labels,omitempty"`
+}
+
+func (c PrometheusRemoteReadYAML) MarshalJSON() ([]byte, error) {
+	type plain PrometheusRemoteReadYAML
+	return marshalWithHTTPClientConfig(plain(c), c.HTTPClientConfig)
+}
+
+func (c *PrometheusRemoteReadYAML) UnmarshalJSON(data []byte) error {
+	type plain PrometheusRemoteReadYAML
+	return unmarshalWithHTTPClientConfig(data, (*plain)(c), &c.HTTPClientConfig)
+}
+
+// id is the name of the config, or its URL when it has none.
+func (c *PrometheusRemoteReadYAML) id() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.URL
+}
+
+func validateRemoteURL(url string) error {
+	if url == "" {
+		return errors.New("url is required")
+	}
+	u, err := urlpkg.Parse(url)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid url %q: scheme must be http or https", url)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid url %q: host is required", url)
+	}
+	return nil
+}
+
+// validateRemote checks the remote_write and remote_read sections: every
+// config has a valid URL and the names given are unique.
+func (py *PrometheusYAML) validateRemote() error {
+	names := map[string]bool{}
+	for _, rw := range py.RemoteWrite {
+		if err := validateRemoteURL(rw.URL); err != nil {
+			return fmt.Errorf("remote write %q: %w", rw.id(), err)
+		}
+		if err := rw.HTTPClientConfig.Validate(); err != nil {
+			return fmt.Errorf("remote write %q: %w", rw.id(), err)
+		}
+		if _, err := relabelConfigs(rw.WriteRelabelConfigs); err != nil {
+			return fmt.Errorf("remote write %q: write_relabel_configs: %w", rw.id(), err)
+		}
+		if rw.Name == "" {
+			continue
+		}
+		if names[rw.Name] {
+			return fmt.Errorf("remote write %q: %w", rw.Name, ErrAlreadyExists)
+		}
+		names[rw.Name] = true
+	}
+
+	names = map[string]bool{}
+	for _, rr := range py.RemoteRead {
+		if err := validateRemoteURL(rr.URL); err != nil {
+			return fmt.Errorf("remote read %q: %w", rr.id(), err)
+		}
+		if err := rr.HTTPClientConfig.Validate(); err != nil {
+			return fmt.Errorf("remote read %q: %w", rr.id(), err)
+		}
+		if rr.Name == "" {
+			continue
+		}
+		if names[rr.Name] {
+			return fmt.Errorf("remote read %q: %w", rr.Name, ErrAlreadyExists)
+		}
+		names[rr.Name] = true
+	}
+	return nil
+}
+
+func (py *PrometheusYAML) remoteWriteIndex(id string) int {
+	for i := range py.RemoteWrite {
+		if py.RemoteWrite[i].id() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (py *PrometheusYAML) remoteReadIndex(id string) int {
+	for i := range py.RemoteRead {
+		if py.RemoteRead[i].id() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (pa *prometheusAPI) GetRemoteWrite(id string) (*PrometheusRemoteWriteYAML, error) {
+	py := pa.ConfigYAML()
+
+	i := py.remoteWriteIndex(id)
+	if i < 0 {
+		return nil, fmt.Errorf("remote write %q: %w", id, ErrNotFound)
+	}
+	out := py.RemoteWrite[i]
+	return &out, nil
+}
+
+func (pa *prometheusAPI) AddRemoteWrite(ctx context.Context, rw *PrometheusRemoteWriteYAML) error {
+	return pa.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		if py.remoteWriteIndex(rw.id()) >= 0 {
+			return fmt.Errorf("remote write %q: %w", rw.id(), ErrAlreadyExists)
+		}
+		py.RemoteWrite = append(py.RemoteWrite, *rw)
+		return nil
+	})
+}
+
+func (pa *prometheusAPI) UpdateRemoteWrite(ctx context.Context, rw *PrometheusRemoteWriteYAML) error {
+	return pa.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		i := py.remoteWriteIndex(rw.id())
+		if i < 0 {
+			return fmt.Errorf("remote write %q: %w", rw.id(), ErrNotFound)
+		}
+		py.RemoteWrite[i] = *rw
+		return nil
+	})
+}
+
+func (pa *prometheusAPI) RemoveRemoteWrite(ctx context.Context, id string) error {
+	return pa.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		i := py.remoteWriteIndex(id)
+		if i < 0 {
+			return fmt.Errorf("remote write %q: %w", id, ErrNotFound)
+		}
+		py.RemoteWrite = append(py.RemoteWrite[:i], py.RemoteWrite[i+1:]...)
+		return nil
+	})
+}
+
+func (pa *prometheusAPI) GetRemoteRead(id string) (*PrometheusRemoteReadYAML, error) {
+	py := pa.ConfigYAML()
+
+	i := py.remoteReadIndex(id)
+	if i < 0 {
+		return nil, fmt.Errorf("remote read %q: %w", id, ErrNotFound)
+	}
+	out := py.RemoteRead[i]
+	return &out, nil
+}
+
+func (pa *prometheusAPI) AddRemoteRead(ctx context.Context, rr *PrometheusRemoteReadYAML) error {
+	return pa.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		if py.remoteReadIndex(rr.id()) >= 0 {
+			return fmt.Errorf("remote read %q: %w", rr.id(), ErrAlreadyExists)
+		}
+		py.RemoteRead = append(py.RemoteRead, *rr)
+		return nil
+	})
+}
+
+func (pa *prometheusAPI) UpdateRemoteRead(ctx context.Context, rr *PrometheusRemoteReadYAML) error {
+	return pa.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		i := py.remoteReadIndex(rr.id())
+		if i < 0 {
+			return fmt.Errorf("remote read %q: %w", rr.id(), ErrNotFound)
+		}
+		py.RemoteRead[i] = *rr
+		return nil
+	})
+}
+
+func (pa *prometheusAPI) RemoveRemoteRead(ctx context.Context, id string) error {
+	return pa.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		i := py.remoteReadIndex(id)
+		if i < 0 {
+			return fmt.Errorf("remote read %q: %w", id, ErrNotFound)
+		}
+		py.RemoteRead = append(py.RemoteRead[:i], py.RemoteRead[i+1:]...)
+		return nil
+	})
+}
@@ -0,0 +1,130 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusAPI_RemoteWrite(t *testing.T) {
+	reloadStatus := http.StatusOK
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/-/reload" && reloadStatus != http.StatusOK {
+			http.Error(w, "failed to reload config", reloadStatus)
+		}
+	}))
+	ctx := context.Background()
+	dst := api.(*prometheusAPI).cfg.ConfigYAML
+
+	disabled, goMetrics := false, "go_.*"
+	rw := &PrometheusRemoteWriteYAML{
+		URL:  "https://mimir.example.com/api/v1/push",
+		Name: "mimir",
+		WriteRelabelConfigs: []RelabelConfigYAML{
+			{SourceLabels: []string{"__name__"}, Regex: &goMetrics, Action: RelabelDrop},
+		},
+		HTTPClientConfig: config.HTTPClientConfig{
+			BasicAuth: &config.BasicAuth{Username: "tenant", Password: "secret"},
+		},
+		QueueConfig:    &RemoteWriteQueueConfigYAML{MaxShards: 10},
+		MetadataConfig: &RemoteWriteMetadataConfigYAML{Send: &disabled},
+	}
+	assert.NoError(t, api.AddRemoteWrite(ctx, rw))
+	assert.True(t, errors.Is(api.AddRemoteWrite(ctx, rw), ErrAlreadyExists))
+	assert.Error(t, api.AddRemoteWrite(ctx, &PrometheusRemoteWriteYAML{URL: "mimir:9009"}))
+	assert.Error(t, api.AddRemoteWrite(ctx, &PrometheusRemoteWriteYAML{}))
+
+	data, _ := os.ReadFile(dst)
+	assert.Contains(t, string(data), "remote_write:\n  - basic_auth:\n      password: secret\n      username: tenant\n")
+	assert.Contains(t, string(data), "    metadata_config:\n      send: false\n")
+
+	got, err := api.GetRemoteWrite("mimir")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "tenant", got.HTTPClientConfig.BasicAuth.Username)
+	if assert.NotNil(t, got.MetadataConfig.Send) {
+		assert.False(t, *got.MetadataConfig.Send)
+	}
+	assert.Equal(t, RelabelDrop, got.WriteRelabelConfigs[0].Action)
+
+	got.RemoteTimeout = model.Duration(10 * time.Second)
+	assert.NoError(t, api.UpdateRemoteWrite(ctx, got))
+	got, _ = api.GetRemoteWrite("mimir")
+	assert.Equal(t, "10s", got.RemoteTimeout.String())
+	assert.True(t, errors.Is(api.UpdateRemoteWrite(ctx, &PrometheusRemoteWriteYAML{URL: "http://other"}), ErrNotFound))
+
+	// configs without a name are identified by their URL
+	assert.NoError(t, api.AddRemoteWrite(ctx, &PrometheusRemoteWriteYAML{URL: "http://thanos:19291/api/v1/receive"}))
+	_, err = api.GetRemoteWrite("http://thanos:19291/api/v1/receive")
+	assert.NoError(t, err)
+
+	reloadStatus = http.StatusInternalServerError
+	assert.Error(t, api.RemoveRemoteWrite(ctx, "mimir"))
+	_, err = api.GetRemoteWrite("mimir")
+	assert.NoError(t, err)
+
+	reloadStatus = http.StatusOK
+	assert.NoError(t, api.RemoveRemoteWrite(ctx, "mimir"))
+	assert.True(t, errors.Is(api.RemoveRemoteWrite(ctx, "mimir"), ErrNotFound))
+	assert.Len(t, api.ConfigYAML().RemoteWrite, 1)
+}
+
+func TestPrometheusAPI_RemoteRead(t *testing.T) {
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ctx := context.Background()
+
+	rr := &PrometheusRemoteReadYAML{
+		URL:              "http://thanos:10908/api/v1/read",
+		RequiredMatchers: map[string]string{"env": "prod"},
+	}
+	assert.NoError(t, api.AddRemoteRead(ctx, rr))
+	assert.True(t, errors.Is(api.AddRemoteRead(ctx, rr), ErrAlreadyExists))
+
+	got, err := api.GetRemoteRead(rr.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Nil(t, got.FilterExternalLabels)
+	assert.Equal(t, "prod", got.RequiredMatchers["env"])
+	data, _ := os.ReadFile(api.(*prometheusAPI).cfg.ConfigYAML)
+	assert.NotContains(t, string(data), "filter_external_labels")
+
+	got.ReadRecent = true
+	assert.NoError(t, api.UpdateRemoteRead(ctx, got))
+	got, _ = api.GetRemoteRead(rr.URL)
+	assert.True(t, got.ReadRecent)
+
+	assert.NoError(t, api.RemoveRemoteRead(ctx, rr.URL))
+	assert.True(t, errors.Is(api.RemoveRemoteRead(ctx, rr.URL), ErrNotFound))
+}
+
+func TestPrometheusRemoteYAML_Defaults(t *testing.T) {
+	// unset fields are left to the defaults of Prometheus, true for both
+	out, err := marshalYAML(&PrometheusYAML{
+		RemoteWrite: []PrometheusRemoteWriteYAML{{
+			URL:            "http://mimir",
+			MetadataConfig: &RemoteWriteMetadataConfigYAML{SendInterval: model.Duration(time.Minute)},
+		}},
+		RemoteRead: []PrometheusRemoteReadYAML{{URL: "http://thanos"}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(out), "- metadata_config:\n    send_interval: 1m\n")
+	assert.NotContains(t, string(out), "send:")
+	assert.NotContains(t, string(out), "filter_external_labels")
+
+	var rr PrometheusRemoteReadYAML
+	assert.NoError(t, rr.UnmarshalJSON([]byte(`{"url":"http://thanos","filter_external_labels":false}`)))
+	if assert.NotNil(t, rr.FilterExternalLabels) {
+		assert.False(t, *rr.FilterExternalLabels)
+	}
+}
@@ -0,0 +1,164 @@
+package pag
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sigs.k8s.io/yaml"
+)
+
+// RuleGroupFile is a rule file matched by the rule_files of the Prometheus configuration.
+type RuleGroupFile struct {
+	Path   string
+	Groups []RuleGroup
+}
+
+type ruleFile struct {
+	path   string
+	raw    []byte
+	groups RuleGroups
+}
+
+func readRuleFile(src string) (*ruleFile, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return nil, err
+	}
+
+	rf := &ruleFile{path: src, raw: data}
+	if err = yaml.Unmarshal(data, &rf.groups); err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+	return rf, nil
+}
+
+// readRuleFiles reads the files matched by rule_files. The caller must hold pa.mu.
+func (pa *prometheusAPI) readRuleFiles() ([]*ruleFile, error) {
+	seen := map[string]bool{}
+	var files []string
+	for _, pattern := range pa.py.RuleFiles {
+		matches, err := filepath.Glob(pa.resolvePath(pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+	sort.Strings(files)
+
+	out := make([]*ruleFile, 0, len(files))
+	for _, file := range files {
+		rf, err := readRuleFile(file)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rf)
+	}
+	return out, nil
+}
+
+// findRuleGroup returns the rule file holding the named group and the index
+// of the group in it. The caller must hold pa.mu.
+func (pa *prometheusAPI) findRuleGroup(name string) (*ruleFile, int, error) {
+	files, err := pa.readRuleFiles()
+	if err != nil {
+		return nil, -1, err
+	}
+
+	for _, rf := range files {
+		for i, group := range rf.groups.Groups {
+			if group.Name == name {
+				return rf, i, nil
+			}
+		}
+	}
+	return nil, -1, fmt.Errorf("rule group %q: %w", name, ErrNotFound)
+}
+
+// writeRuleFile writes groups to the rule file, or removes it when groups is
+// empty, and reloads Prometheus. The file is restored if the reload fails.
+// The caller must hold pa.mu.
+func (pa *prometheusAPI) writeRuleFile(ctx context.Context, rf *ruleFile, groups []RuleGroup) error {
+	var restore func() error
+	if len(groups) == 0 {
+		if err := os.Remove(rf.path); err != nil {
+			return err
+		}
+		restore = func() error {
+			return writeFileAtomic(rf.path, rf.raw, 0644)
+		}
+	} else {
+		// merge into the original file to keep what RuleGroup does not model
+		data, err := mergeYAML(rf.raw, &rf.groups, &RuleGroups{Groups: groups})
+		if err != nil {
+			return err
+		}
+		if restore, err = replaceFile(rf.path, data); err != nil {
+			return err
+		}
+	}
+
+	if err := pa.Reload(ctx); err != nil {
+		if rerr := restore(); rerr != nil {
+			return fmt.Errorf("reload prometheus: %w (restore %s: %v)", err, rf.path, rerr)
+		}
+		return fmt.Errorf("reload prometheus: %w", err)
+	}
+
+	return nil
+}
+
+func (pa *prometheusAPI) ListRuleGroupFiles() ([]RuleGroupFile, error) {
+	pa.mu.RLock()
+	defer pa.mu.RUnlock()
+
+	files, err := pa.readRuleFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]RuleGroupFile, 0, len(files))
+	for _, rf := range files {
+		out = append(out, RuleGroupFile{Path: rf.path, Groups: rf.groups.Groups})
+	}
+	return out, nil
+}
+
+func (pa *prometheusAPI) UpdateRuleGroup(ctx context.Context, rg *RuleGroup) error {
+	if err := rg.Validate(); err != nil {
+		return err
+	}
+
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	rf, i, err := pa.findRuleGroup(rg.Name)
+	if err != nil {
+		return err
+	}
+
+	groups := append([]RuleGroup(nil), rf.groups.Groups...)
+	groups[i] = *rg
+	return pa.writeRuleFile(ctx, rf, groups)
+}
+
+func (pa *prometheusAPI) DeleteRuleGroup(ctx context.Context, name string) error {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	rf, i, err := pa.findRuleGroup(name)
+	if err != nil {
+		return err
+	}
+
+	groups := append([]RuleGroup(nil), rf.groups.Groups[:i]...)
+	groups = append(groups, rf.groups.Groups[i+1:]...)
+	return pa.writeRuleFile(ctx, rf, groups)
+}
@@ -0,0 +1,186 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPrometheusRulesTestAPI(t *testing.T, reloadStatus *int) (PrometheusAPI, string) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/-/reload" && *reloadStatus != http.StatusOK {
+			http.Error(w, "failed to reload config", *reloadStatus)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "rules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "prometheus.yaml")
+	if err := os.WriteFile(dst, []byte("rule_files:\n  - rules/*.yml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	api, err := NewPrometheusAPI(srv.Client(), &PrometheusConfig{Endpoint: srv.URL, ConfigYAML: dst})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api, filepath.Join(dir, "rules")
+}
+
+func TestPrometheusAPI_RuleGroups(t *testing.T) {
+	reloadStatus := http.StatusOK
+	api, dir := newPrometheusRulesTestAPI(t, &reloadStatus)
+	ctx := context.Background()
+
+	shared := filepath.Join(dir, "shared.yml")
+	assert.NoError(t, os.WriteFile(shared, []byte(`groups:
+  # node alerts
+  - name: node
+    rules:
+      - alert: NodeDown
+        expr: up{job="node"} == 0
+        for: 5m
+  - name: disk
+    rules:
+      - alert: DiskFull
+        expr: node_filesystem_avail_bytes == 0
+`), 0644))
+
+	assert.NoError(t, api.AddRuleGroups(ctx, &RuleGroup{
+		Name:  "tenant-a",
+		Rules: []Rule{{Alert: "TenantDown", Expr: `up{tenant="a"} == 0`}},
+	}))
+
+	files, err := api.ListRuleGroupFiles()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, files, 2) {
+		return
+	}
+	assert.Equal(t, shared, files[0].Path)
+	assert.Equal(t, "disk", files[0].Groups[1].Name)
+	assert.Equal(t, "tenant-a", files[1].Groups[0].Name)
+
+	assert.NoError(t, api.UpdateRuleGroup(ctx, &RuleGroup{
+		Name:  "node",
+		Rules: []Rule{{Alert: "NodeDown", Expr: `up{job="node"} == 0`, For: "10m"}},
+	}))
+	data, _ := os.ReadFile(shared)
+	assert.Contains(t, string(data), "  # node alerts\n  - name: node\n")
+	assert.Contains(t, string(data), "        for: 10m\n")
+	assert.True(t, errors.Is(api.UpdateRuleGroup(ctx, &RuleGroup{Name: "missing"}), ErrNotFound))
+
+	// a refused reload restores the file
+	reloadStatus = http.StatusInternalServerError
+	assert.Error(t, api.DeleteRuleGroup(ctx, "tenant-a"))
+	assert.FileExists(t, filepath.Join(dir, "tenant-a.yml"))
+
+	reloadStatus = http.StatusOK
+	assert.NoError(t, api.DeleteRuleGroup(ctx, "tenant-a"))
+	assert.NoFileExists(t, filepath.Join(dir, "tenant-a.yml"))
+
+	assert.NoError(t, api.DeleteRuleGroup(ctx, "disk"))
+	files, _ = api.ListRuleGroupFiles()
+	assert.Len(t, files, 1)
+	assert.Len(t, files[0].Groups, 1)
+	assert.True(t, errors.Is(api.DeleteRuleGroup(ctx, "disk"), ErrNotFound))
+}
+
+func TestRuleGroup_Validate(t *testing.T) {
+	rg := &RuleGroup{
+		Name:        "node",
+		Interval:    "30s",
+		QueryOffset: "1m",
+		Limit:       10,
+		Rules: []Rule{
+			{Record: "job:up:sum", Expr: "sum by (job) (up)", Labels: map[string]string{"team": "sre"}},
+			{Alert: "NodeDown", Expr: "up == 0", For: "5m", KeepFiringFor: "10m"},
+		},
+	}
+	assert.NoError(t, rg.Validate())
+
+	invalid := []Rule{
+		{Expr: "up"},
+		{Alert: "A", Record: "b", Expr: "up"},
+		{Alert: "A"},
+		{Record: "b", Expr: "up", For: "5m"},
+		{Record: "b", Expr: "up", KeepFiringFor: "5m"},
+		{Record: "b", Expr: "up", Annotations: map[string]string{"summary": "s"}},
+	}
+	for _, rule := range invalid {
+		assert.Error(t, rule.Validate(), "%+v", rule)
+	}
+	assert.Error(t, (&RuleGroup{}).Validate())
+
+	reloadStatus := http.StatusOK
+	api, dir := newPrometheusRulesTestAPI(t, &reloadStatus)
+	ctx := context.Background()
+
+	assert.NoError(t, api.AddRuleGroups(ctx, rg))
+	data, _ := os.ReadFile(filepath.Join(dir, "node.yml"))
+	assert.Equal(t, `groups:
+- interval: 30s
+  limit: 10
+  name: node
+  query_offset: 1m
+  rules:
+  - expr: sum by (job) (up)
+    labels:
+      team: sre
+    record: job:up:sum
+  - alert: NodeDown
+    expr: up == 0
+    for: 5m
+    keep_firing_for: 10m
+`, string(data))
+
+	assert.Error(t, api.AddRuleGroups(ctx, &RuleGroup{Name: "bad", Rules: invalid}))
+	assert.NoFileExists(t, filepath.Join(dir, "bad.yml"))
+}
+
+func TestRuleGroup_ValidatePromQL(t *testing.T) {
+	rg := &RuleGroup{
+		Name:     "broken",
+		Interval: "30x",
+		Rules: []Rule{
+			{Alert: "Ok", Expr: `rate(http_requests_total[5m]) > 1`, Annotations: map[string]string{"summary": "{{ $labels.instance }} is at {{ $value }}"}},
+			{Alert: "BadExpr", Expr: `rate(http_requests_total[5m]`, For: "5 minutes"},
+			{Alert: "BadTemplate", Expr: `up == 0`, Annotations: map[string]string{"summary": "{{ $labels.instance "}},
+			{Record: "bad-name", Expr: `sum(up)`, Labels: map[string]string{"0team": "sre"}},
+		},
+	}
+
+	err := rg.Validate()
+	var errs RuleErrors
+	if !assert.True(t, errors.As(err, &errs)) {
+		return
+	}
+
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, fmt.Sprintf("%d:%s:%s", e.Rule, e.Name, e.Field))
+	}
+	assert.Equal(t, []string{
+		"-1::interval",
+		"1:BadExpr:expr",
+		"1:BadExpr:for",
+		"2:BadTemplate:annotations.summary",
+		"3:bad-name:record",
+		"3:bad-name:labels.0team",
+	}, fields)
+	assert.Contains(t, errs[1].Error(), `rule group "broken", rule 1 (BadExpr), expr: `)
+
+	var ruleErr *RuleError
+	assert.True(t, errors.As(err, &ruleErr))
+}
@@ -0,0 +1,222 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/template"
+)
+
+// RuleError is a problem found in a rule group before it is written.
+type RuleError struct {
+	Group string
+	// Rule is the index of the rule in the group, -1 for the group itself.
+	Rule int
+	// Name is the alert or record name of the rule.
+	Name string
+	// Field is the offending field, e.g. "expr" or "annotations.summary".
+	Field string
+
+	Err error
+}
+
+func (e *RuleError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "rule group %q", e.Group)
+	if e.Rule >= 0 {
+		fmt.Fprintf(&b, ", rule %d", e.Rule)
+		if e.Name != "" {
+			fmt.Fprintf(&b, " (%s)", e.Name)
+		}
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, ", %s", e.Field)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	return b.String()
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// RuleErrors are all the problems found in a rule group.
+type RuleErrors []*RuleError
+
+func (es RuleErrors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (es RuleErrors) Unwrap() []error {
+	out := make([]error, 0, len(es))
+	for _, e := range es {
+		out = append(out, e)
+	}
+	return out
+}
+
+// Validate checks the group offline the way Prometheus does when loading it:
+// every rule is exactly one of an alerting or a recording rule, expressions
+// parse as PromQL, durations, label and annotation names are valid, and the
+// templates of alerting rules parse. All problems are returned as RuleErrors.
+func (rg *RuleGroup) Validate() error {
+	var errs RuleErrors
+	groupErr := func(field string, err error) {
+		errs = append(errs, &RuleError{Group: rg.Name, Rule: -1, Field: field, Err: err})
+	}
+
+	if rg.Name == "" {
+		groupErr("name", errors.New("rule group name is required"))
+	}
+	if err := validateDuration(rg.Interval); err != nil {
+		groupErr("interval", err)
+	}
+	if err := validateDuration(rg.QueryOffset); err != nil {
+		groupErr("query_offset", err)
+	}
+	if rg.Limit < 0 {
+		groupErr("limit", errors.New("limit must not be negative"))
+	}
+
+	for i := range rg.Rules {
+		for _, e := range rg.Rules[i].validate() {
+			e.Group = rg.Name
+			e.Rule = i
+			errs = append(errs, e)
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate checks a single rule, see RuleGroup.Validate.
+func (r *Rule) Validate() error {
+	errs := r.validate()
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+func (r *Rule) validate() RuleErrors {
+	var errs RuleErrors
+	fieldErr := func(field string, err error) {
+		errs = append(errs, &RuleError{Rule: -1, Name: r.Alert + r.Record, Field: field, Err: err})
+	}
+
+	switch {
+	case r.Alert != "" && r.Record != "":
+		fieldErr("", errors.New("only one of alert and record can be set"))
+	case r.Alert == "" && r.Record == "":
+		fieldErr("", errors.New("one of alert and record must be set"))
+	case r.Record != "" && !model.IsValidMetricName(model.LabelValue(r.Record)):
+		fieldErr("record", fmt.Errorf("invalid recording rule name %q", r.Record))
+	}
+
+	if r.Expr == "" {
+		fieldErr("expr", errors.New("expr is required"))
+	} else if _, err := parser.ParseExpr(r.Expr); err != nil {
+		fieldErr("expr", err)
+	}
+
+	if r.Record != "" {
+		if r.For != "" {
+			fieldErr("for", errors.New("invalid field 'for' in recording rule"))
+		}
+		if r.KeepFiringFor != "" {
+			fieldErr("keep_firing_for", errors.New("invalid field 'keep_firing_for' in recording rule"))
+		}
+		if len(r.Annotations) != 0 {
+			fieldErr("annotations", errors.New("invalid field 'annotations' in recording rule"))
+		}
+	}
+	if err := validateDuration(r.For); err != nil {
+		fieldErr("for", err)
+	}
+	if err := validateDuration(r.KeepFiringFor); err != nil {
+		fieldErr("keep_firing_for", err)
+	}
+
+	for _, name := range sortedKeys(r.Labels) {
+		if !model.LabelName(name).IsValid() || name == model.MetricNameLabel {
+			fieldErr("labels."+name, fmt.Errorf("invalid label name %q", name))
+		}
+		if !utf8.ValidString(r.Labels[name]) {
+			fieldErr("labels."+name, fmt.Errorf("invalid label value %q", r.Labels[name]))
+		}
+		if r.Alert != "" {
+			if err := parseAlertTemplate(r.Alert, r.Labels[name]); err != nil {
+				fieldErr("labels."+name, err)
+			}
+		}
+	}
+	for _, name := range sortedKeys(r.Annotations) {
+		if !model.LabelName(name).IsValid() {
+			fieldErr("annotations."+name, fmt.Errorf("invalid annotation name %q", name))
+		}
+		if r.Alert != "" {
+			if err := parseAlertTemplate(r.Alert, r.Annotations[name]); err != nil {
+				fieldErr("annotations."+name, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateDuration(s string) error {
+	if s == "" {
+		return nil
+	}
+	_, err := model.ParseDuration(s)
+	return err
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseAlertTemplate parses text as the template of an alert label or
+// annotation, with the same variables Prometheus defines for it.
+func parseAlertTemplate(alert, text string) error {
+	defs := []string{
+		"{{$labels := .Labels}}",
+		"{{$externalLabels := .ExternalLabels}}",
+		"{{$externalURL := .ExternalURL}}",
+		"{{$value := .Value}}",
+	}
+	data := template.AlertTemplateData(map[string]string{}, map[string]string{}, "", promql.Sample{})
+
+	tmpl := template.NewTemplateExpander(
+		context.TODO(),
+		strings.Join(append(defs, text), ""),
+		"__alert_"+alert,
+		data,
+		model.Time(timestamp.FromTime(time.Now())),
+		nil,
+		nil,
+		nil,
+	)
+	return tmpl.ParseTest()
+}
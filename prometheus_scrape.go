@@ -0,0 +1,96 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Validate checks the alerting section, that every scrape config has a unique
+// job name, valid service discovery and relabel configs, and that the remote
+// write and remote read configs have valid URLs and unique names.
+func (py *PrometheusYAML) Validate() error {
+	if py.Alerting != nil {
+		if err := py.Alerting.Validate(); err != nil {
+			return fmt.Errorf("alerting: %w", err)
+		}
+	}
+
+	jobs := map[string]bool{}
+	for _, sc := range py.ScrapeConfigs {
+		if sc.JobName == "" {
+			return errors.New("job_name is required")
+		}
+		if jobs[sc.JobName] {
+			return fmt.Errorf("scrape config %q: %w", sc.JobName, ErrAlreadyExists)
+		}
+		jobs[sc.JobName] = true
+
+		if err := sc.validateSD(); err != nil {
+			return fmt.Errorf("scrape config %q: %w", sc.JobName, err)
+		}
+		if _, err := relabelConfigs(sc.RelabelConfigs); err != nil {
+			return fmt.Errorf("scrape config %q: relabel_configs: %w", sc.JobName, err)
+		}
+		if _, err := relabelConfigs(sc.MetricRelabelConfigs); err != nil {
+			return fmt.Errorf("scrape config %q: metric_relabel_configs: %w", sc.JobName, err)
+		}
+	}
+	return py.validateRemote()
+}
+
+func (py *PrometheusYAML) scrapeConfigIndex(jobName string) int {
+	for i, sc := range py.ScrapeConfigs {
+		if sc.JobName == jobName {
+			return i
+		}
+	}
+	return -1
+}
+
+func (pa *prometheusAPI) GetScrapeConfig(jobName string) (*PrometheusScrapeConfigYAML, error) {
+	py := pa.ConfigYAML()
+
+	i := py.scrapeConfigIndex(jobName)
+	if i < 0 {
+		return nil, fmt.Errorf("scrape config %q: %w", jobName, ErrNotFound)
+	}
+	out := py.ScrapeConfigs[i]
+	return &out, nil
+}
+
+func (pa *prometheusAPI) AddScrapeConfig(ctx context.Context, sc *PrometheusScrapeConfigYAML) error {
+	if sc.JobName == "" {
+		return errors.New("job_name is required")
+	}
+
+	return pa.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		if py.scrapeConfigIndex(sc.JobName) >= 0 {
+			return fmt.Errorf("scrape config %q: %w", sc.JobName, ErrAlreadyExists)
+		}
+		py.ScrapeConfigs = append(py.ScrapeConfigs, *sc)
+		return nil
+	})
+}
+
+func (pa *prometheusAPI) UpdateScrapeConfig(ctx context.Context, sc *PrometheusScrapeConfigYAML) error {
+	return pa.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		i := py.scrapeConfigIndex(sc.JobName)
+		if i < 0 {
+			return fmt.Errorf("scrape config %q: %w", sc.JobName, ErrNotFound)
+		}
+		py.ScrapeConfigs[i] = *sc
+		return nil
+	})
+}
+
+func (pa *prometheusAPI) RemoveScrapeConfig(ctx context.Context, jobName string) error {
+	return pa.UpdateConfigYAML(ctx, func(py *PrometheusYAML) error {
+		i := py.scrapeConfigIndex(jobName)
+		if i < 0 {
+			return fmt.Errorf("scrape config %q: %w", jobName, ErrNotFound)
+		}
+		py.ScrapeConfigs = append(py.ScrapeConfigs[:i], py.ScrapeConfigs[i+1:]...)
+		return nil
+	})
+}
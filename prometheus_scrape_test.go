@@ -0,0 +1,88 @@
+package pag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusAPI_ScrapeConfigs(t *testing.T) {
+	reloadStatus := http.StatusOK
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/-/reload" && reloadStatus != http.StatusOK {
+			http.Error(w, "failed to reload config", reloadStatus)
+		}
+	}))
+	ctx := context.Background()
+	dst := api.(*prometheusAPI).cfg.ConfigYAML
+
+	sc := &PrometheusScrapeConfigYAML{
+		JobName:        "node",
+		ScrapeInterval: model.Duration(30 * time.Second),
+		StaticConfig: []ServiceDiscoveryEndpoint{
+			{Targets: []string{"127.0.0.1:9100"}},
+		},
+	}
+	assert.NoError(t, api.AddScrapeConfig(ctx, sc))
+	assert.True(t, errors.Is(api.AddScrapeConfig(ctx, sc), ErrAlreadyExists))
+	assert.Error(t, api.AddScrapeConfig(ctx, &PrometheusScrapeConfigYAML{}))
+
+	got, err := api.GetScrapeConfig("node")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "30s", got.ScrapeInterval.String())
+
+	data, _ := os.ReadFile(dst)
+	assert.Contains(t, string(data), "  - job_name: node\n    scrape_interval: 30s\n")
+
+	got.MetricsPath = "/probe"
+	assert.NoError(t, api.UpdateScrapeConfig(ctx, got))
+	got, _ = api.GetScrapeConfig("node")
+	assert.Equal(t, "/probe", got.MetricsPath)
+	assert.True(t, errors.Is(api.UpdateScrapeConfig(ctx, &PrometheusScrapeConfigYAML{JobName: "missing"}), ErrNotFound))
+
+	// a refused reload leaves the job in place
+	reloadStatus = http.StatusInternalServerError
+	assert.Error(t, api.RemoveScrapeConfig(ctx, "node"))
+	_, err = api.GetScrapeConfig("node")
+	assert.NoError(t, err)
+
+	reloadStatus = http.StatusOK
+	assert.NoError(t, api.RemoveScrapeConfig(ctx, "node"))
+	_, err = api.GetScrapeConfig("node")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(api.RemoveScrapeConfig(ctx, "node"), ErrNotFound))
+}
+
+func TestPrometheusAPI_AddScrapeConfigDefaults(t *testing.T) {
+	api := newPrometheusTestAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ctx := context.Background()
+
+	// a zero value leaves honor_timestamps and enable_compression to Prometheus
+	if !assert.NoError(t, api.AddScrapeConfig(ctx, &PrometheusScrapeConfigYAML{JobName: "zero"})) {
+		return
+	}
+	data, _ := os.ReadFile(api.(*prometheusAPI).cfg.ConfigYAML)
+	assert.Contains(t, string(data), "  - job_name: zero\n")
+	assert.NotContains(t, string(data), "honor_timestamps")
+	assert.NotContains(t, string(data), "enable_compression")
+	assert.NotContains(t, string(data), "track_timestamps_staleness")
+
+	disabled := false
+	assert.NoError(t, api.AddScrapeConfig(ctx, &PrometheusScrapeConfigYAML{JobName: "raw", HonorTimestamps: &disabled}))
+	data, _ = os.ReadFile(api.(*prometheusAPI).cfg.ConfigYAML)
+	assert.Contains(t, string(data), "  - honor_timestamps: false\n    job_name: raw\n")
+
+	got, err := api.GetScrapeConfig("zero")
+	if assert.NoError(t, err) {
+		assert.Nil(t, got.HonorTimestamps)
+		assert.Nil(t, got.EnableCompression)
+	}
+}
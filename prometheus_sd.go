@@ -0,0 +1,264 @@
+package pag
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+)
+
+type HTTPSDConfig struct {
+	// URL from which the targets are fetched.
+	URL string `json:"url"`
+	// Refresh interval to re-query the endpoint.
+	RefreshInterval model.Duration `json:"refresh_interval,omitempty"`
+
+	HTTPClientConfig config.HTTPClientConfig `json:"-"`
+}
+
+func (c HTTPSDConfig) MarshalJSON() ([]byte, error) {
+	type plain HTTPSDConfig
+	return marshalWithHTTPClientConfig(plain(c), c.HTTPClientConfig)
+}
+
+func (c *HTTPSDConfig) UnmarshalJSON(data []byte) error {
+	type plain HTTPSDConfig
+	return unmarshalWithHTTPClientConfig(data, (*plain)(c), &c.HTTPClientConfig)
+}
+
+func (c *HTTPSDConfig) Validate() error {
+	if err := validateRemoteURL(c.URL); err != nil {
+		return err
+	}
+	return c.HTTPClientConfig.Validate()
+}
+
+type DNSSDConfig struct {
+	// A list of DNS domain names to be queried.
+	Names []string `json:"names"`
+	// The type of DNS query to perform: SRV (the default), A, AAAA, MX or NS.
+	Type string `json:"type,omitempty"`
+	// The port number used if the query type is not SRV.
+	Port int `json:"port,omitempty"`
+	// The time after which the provided names are refreshed.
+	RefreshInterval model.Duration `json:"refresh_interval,omitempty"`
+}
+
+func (c *DNSSDConfig) Validate() error {
+	if len(c.Names) == 0 {
+		return errors.New("DNS-SD config must contain at least one SRV record name")
+	}
+	for _, name := range c.Names {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("DNS-SD config contains an empty name")
+		}
+	}
+	switch strings.ToUpper(c.Type) {
+	case "", "SRV":
+	case "A", "AAAA", "MX", "NS":
+		if c.Port == 0 {
+			return errors.New("a port is required in DNS-SD configs for all record types except SRV")
+		}
+	default:
+		return fmt.Errorf("invalid DNS-SD records type %s", c.Type)
+	}
+	return nil
+}
+
+type ConsulSDConfig struct {
+	// The information to access the Consul API, localhost:8500 by default.
+	Server     string        `json:"server,omitempty"`
+	PathPrefix string        `json:"path_prefix,omitempty"`
+	Token      config.Secret `json:"token,omitempty"`
+	Datacenter string        `json:"datacenter,omitempty"`
+	// Namespaces and admin partitions are only supported in Consul Enterprise.
+	Namespace string `json:"namespace,omitempty"`
+	Partition string `json:"partition,omitempty"`
+	Scheme    string `json:"scheme,omitempty"`
+	// A list of services for which targets are retrieved, all services by default.
+	Services []string `json:"services,omitempty"`
+	// A Consul filter expression used to filter the catalog results.
+	Filter string `json:"filter,omitempty"`
+	// An optional list of tags used to filter nodes for a given service.
+	Tags []string `json:"tags,omitempty"`
+	// Node metadata key/value pairs to filter nodes for a given service.
+	NodeMeta map[string]string `json:"node_meta,omitempty"`
+	// The string by which Consul tags are joined into the tag label.
+	TagSeparator string `json:"tag_separator,omitempty"`
+	// Allow stale Consul results.
+	AllowStale bool `json:"allow_stale,omitempty"`
+	// The time after which the provided names are refreshed.
+	RefreshInterval model.Duration `json:"refresh_interval,omitempty"`
+
+	HTTPClientConfig config.HTTPClientConfig `json:"-"`
+}
+
+func (c ConsulSDConfig) MarshalJSON() ([]byte, error) {
+	type plain ConsulSDConfig
+	return marshalWithHTTPClientConfig(plain(c), c.HTTPClientConfig)
+}
+
+func (c *ConsulSDConfig) UnmarshalJSON(data []byte) error {
+	type plain ConsulSDConfig
+	return unmarshalWithHTTPClientConfig(data, (*plain)(c), &c.HTTPClientConfig)
+}
+
+func (c *ConsulSDConfig) Validate() error {
+	if c.Server != "" && strings.TrimSpace(c.Server) == "" {
+		return errors.New("consul SD configuration requires a server address")
+	}
+	switch c.Scheme {
+	case "", "http", "https":
+	default:
+		return fmt.Errorf("invalid consul SD scheme %q", c.Scheme)
+	}
+	if c.Token != "" && (c.HTTPClientConfig.Authorization != nil || c.HTTPClientConfig.OAuth2 != nil) {
+		return errors.New("at most one of consul SD token, authorization, or oauth2 can be configured")
+	}
+	return c.HTTPClientConfig.Validate()
+}
+
+type KubernetesRole string
+
+const (
+	KubernetesRoleNode          KubernetesRole = "node"
+	KubernetesRolePod           KubernetesRole = "pod"
+	KubernetesRoleService       KubernetesRole = "service"
+	KubernetesRoleEndpoints     KubernetesRole = "endpoints"
+	KubernetesRoleEndpointSlice KubernetesRole = "endpointslice"
+	KubernetesRoleIngress       KubernetesRole = "ingress"
+)
+
+func (r KubernetesRole) valid() bool {
+	switch r {
+	case KubernetesRoleNode, KubernetesRolePod, KubernetesRoleService,
+		KubernetesRoleEndpoints, KubernetesRoleEndpointSlice, KubernetesRoleIngress:
+		return true
+	}
+	return false
+}
+
+type KubernetesSDConfig struct {
+	// The API server addresses, in-cluster discovery is used when empty.
+	APIServer string `json:"api_server,omitempty"`
+	// The Kubernetes role of entities that should be discovered.
+	Role KubernetesRole `json:"role"`
+	// Path to a kubeconfig file, mutually exclusive with api_server.
+	KubeConfig string `json:"kubeconfig_file,omitempty"`
+
+	HTTPClientConfig config.HTTPClientConfig `json:"-"`
+
+	// Optional namespace discovery, all namespaces are used when omitted.
+	NamespaceDiscovery *KubernetesNamespaceDiscovery `json:"namespaces,omitempty"`
+	// Optional label and field selectors to limit the discovery process.
+	Selectors []KubernetesSelectorConfig `json:"selectors,omitempty"`
+	// Optional metadata to attach to discovered targets.
+	AttachMetadata *KubernetesAttachMetadataConfig `json:"attach_metadata,omitempty"`
+}
+
+type KubernetesNamespaceDiscovery struct {
+	IncludeOwnNamespace bool     `json:"own_namespace,omitempty"`
+	Names               []string `json:"names,omitempty"`
+}
+
+type KubernetesSelectorConfig struct {
+	Role  KubernetesRole `json:"role"`
+	Label string         `json:"label,omitempty"`
+	Field string         `json:"field,omitempty"`
+}
+
+type KubernetesAttachMetadataConfig struct {
+	// Attaches node metadata to discovered targets, for the pod, endpoints and endpointslice roles.
+	Node bool `json:"node,omitempty"`
+}
+
+func (c KubernetesSDConfig) MarshalJSON() ([]byte, error) {
+	type plain KubernetesSDConfig
+	return marshalWithHTTPClientConfig(plain(c), c.HTTPClientConfig)
+}
+
+func (c *KubernetesSDConfig) UnmarshalJSON(data []byte) error {
+	type plain KubernetesSDConfig
+	return unmarshalWithHTTPClientConfig(data, (*plain)(c), &c.HTTPClientConfig)
+}
+
+// selectorRoles are the selector roles allowed for each discovery role.
+var selectorRoles = map[KubernetesRole][]KubernetesRole{
+	KubernetesRoleNode:          {KubernetesRoleNode},
+	KubernetesRolePod:           {KubernetesRolePod, KubernetesRoleNode},
+	KubernetesRoleService:       {KubernetesRoleService},
+	KubernetesRoleEndpoints:     {KubernetesRolePod, KubernetesRoleService, KubernetesRoleEndpoints},
+	KubernetesRoleEndpointSlice: {KubernetesRolePod, KubernetesRoleService, KubernetesRoleEndpointSlice},
+	KubernetesRoleIngress:       {KubernetesRoleIngress},
+}
+
+func (c *KubernetesSDConfig) Validate() error {
+	if c.Role == "" {
+		return errors.New("role missing (one of: pod, service, endpoints, endpointslice, node, ingress)")
+	}
+	if !c.Role.valid() {
+		return fmt.Errorf("unknown Kubernetes SD role %q", c.Role)
+	}
+	if err := c.HTTPClientConfig.Validate(); err != nil {
+		return err
+	}
+	if c.APIServer != "" && c.KubeConfig != "" {
+		return errors.New("cannot use 'kubeconfig_file' and 'api_server' simultaneously")
+	}
+	if c.APIServer == "" && !reflect.DeepEqual(c.HTTPClientConfig, config.HTTPClientConfig{}) &&
+		!reflect.DeepEqual(c.HTTPClientConfig, config.DefaultHTTPClientConfig) {
+		return errors.New("to use custom HTTP client configuration please provide the 'api_server' URL explicitly")
+	}
+	if c.APIServer != "" {
+		if err := validateRemoteURL(c.APIServer); err != nil {
+			return fmt.Errorf("api_server: %w", err)
+		}
+	}
+
+	seen := map[KubernetesRole]bool{}
+	for _, s := range c.Selectors {
+		allowed := false
+		for _, r := range selectorRoles[c.Role] {
+			if s.Role == r {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%s role supports only %v selectors", c.Role, selectorRoles[c.Role])
+		}
+		if seen[s.Role] {
+			return fmt.Errorf("duplicated selector role: %s", s.Role)
+		}
+		seen[s.Role] = true
+	}
+	return nil
+}
+
+// validateSD checks the service discovery configs of the scrape config.
+func (sc *PrometheusScrapeConfigYAML) validateSD() error {
+	for i := range sc.HTTPSDConfigs {
+		if err := sc.HTTPSDConfigs[i].Validate(); err != nil {
+			return fmt.Errorf("http_sd_configs %d: %w", i, err)
+		}
+	}
+	for i := range sc.DNSSDConfigs {
+		if err := sc.DNSSDConfigs[i].Validate(); err != nil {
+			return fmt.Errorf("dns_sd_configs %d: %w", i, err)
+		}
+	}
+	for i := range sc.ConsulSDConfigs {
+		if err := sc.ConsulSDConfigs[i].Validate(); err != nil {
+			return fmt.Errorf("consul_sd_configs %d: %w", i, err)
+		}
+	}
+	for i := range sc.KubernetesSDConfigs {
+		if err := sc.KubernetesSDConfigs[i].Validate(); err != nil {
+			return fmt.Errorf("kubernetes_sd_configs %d: %w", i, err)
+		}
+	}
+	return nil
+}
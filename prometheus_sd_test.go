@@ -0,0 +1,98 @@
+package pag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+const sdConfigYAML = `
+scrape_configs:
+  - job_name: sd
+    http_sd_configs:
+      - url: http://sd.example.com/targets
+        refresh_interval: 30s
+        basic_auth:
+          username: prometheus
+          password: secret
+    dns_sd_configs:
+      - names: [_node._tcp.example.com]
+      - names: [db.example.com]
+        type: A
+        port: 9100
+    consul_sd_configs:
+      - server: consul:8500
+        token: s3cr3t
+        services: [api, web]
+        tags: [prod]
+    kubernetes_sd_configs:
+      - role: pod
+        namespaces:
+          names: [monitoring]
+        selectors:
+          - role: pod
+            label: app=api
+        attach_metadata:
+          node: true
+`
+
+func TestPrometheusScrapeConfigYAML_SDConfigs(t *testing.T) {
+	var py PrometheusYAML
+	if !assert.NoError(t, yaml.Unmarshal([]byte(sdConfigYAML), &py)) {
+		return
+	}
+	assert.NoError(t, py.Validate())
+
+	sc := py.ScrapeConfigs[0]
+	assert.Equal(t, "prometheus", sc.HTTPSDConfigs[0].HTTPClientConfig.BasicAuth.Username)
+	assert.Equal(t, "30s", sc.HTTPSDConfigs[0].RefreshInterval.String())
+	assert.Equal(t, 9100, sc.DNSSDConfigs[1].Port)
+	assert.Equal(t, "s3cr3t", string(sc.ConsulSDConfigs[0].Token))
+	assert.Equal(t, KubernetesRolePod, sc.KubernetesSDConfigs[0].Role)
+	assert.Equal(t, []string{"monitoring"}, sc.KubernetesSDConfigs[0].NamespaceDiscovery.Names)
+	assert.True(t, sc.KubernetesSDConfigs[0].AttachMetadata.Node)
+
+	data, err := marshalYAML(&py)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "  - basic_auth:\n      password: secret\n      username: prometheus\n    refresh_interval: 30s\n    url: http://sd.example.com/targets\n")
+	assert.Contains(t, string(data), "token: s3cr3t")
+
+	var out PrometheusYAML
+	assert.NoError(t, yaml.Unmarshal(data, &out))
+	assert.Equal(t, py, out)
+}
+
+func TestSDConfigs_Validate(t *testing.T) {
+	invalid := []interface{ Validate() error }{
+		&HTTPSDConfig{},
+		&HTTPSDConfig{URL: "sd.example.com/targets"},
+		&DNSSDConfig{},
+		&DNSSDConfig{Names: []string{"db.example.com"}, Type: "A"},
+		&DNSSDConfig{Names: []string{"db.example.com"}, Type: "TXT"},
+		&ConsulSDConfig{Scheme: "ftp"},
+		&KubernetesSDConfig{},
+		&KubernetesSDConfig{Role: "deployment"},
+		&KubernetesSDConfig{Role: KubernetesRolePod, APIServer: "https://k8s", KubeConfig: "/etc/kubeconfig"},
+		&KubernetesSDConfig{Role: KubernetesRoleNode, Selectors: []KubernetesSelectorConfig{{Role: KubernetesRolePod}}},
+		&KubernetesSDConfig{Role: KubernetesRolePod, Selectors: []KubernetesSelectorConfig{{Role: KubernetesRolePod}, {Role: KubernetesRolePod}}},
+	}
+	for _, c := range invalid {
+		assert.Error(t, c.Validate(), "%+v", c)
+	}
+
+	valid := []interface{ Validate() error }{
+		&HTTPSDConfig{URL: "https://sd.example.com/targets"},
+		&DNSSDConfig{Names: []string{"_node._tcp.example.com"}},
+		&ConsulSDConfig{},
+		&KubernetesSDConfig{Role: KubernetesRoleEndpoints, Selectors: []KubernetesSelectorConfig{{Role: KubernetesRoleService, Label: "app"}}},
+	}
+	for _, c := range valid {
+		assert.NoError(t, c.Validate(), "%+v", c)
+	}
+
+	var py PrometheusYAML
+	assert.NoError(t, yaml.Unmarshal([]byte(sdConfigYAML), &py))
+	py.ScrapeConfigs[0].DNSSDConfigs[1].Port = 0
+	assert.ErrorContains(t, py.Validate(), `scrape config "sd": dns_sd_configs 1`)
+}
@@ -0,0 +1,46 @@
+package pag
+
+import (
+	"context"
+	"time"
+)
+
+const defaultReloadTimeout = 30 * time.Second
+
+// reloadVerifyInterval is how often ReloadAndVerify checks the running configuration.
+var reloadVerifyInterval = 500 * time.Millisecond
+
+// verifyReload calls check until it reports the configuration as applied or
+// ctx is done. check returns applied, or the reason it is not, and whether to
+// stop waiting because the reload definitely failed. On timeout the last
+// reason given is reported.
+func verifyReload(ctx context.Context, service string, check func(ctx context.Context) (applied bool, reason string, failed bool, err error)) error {
+	ticker := time.NewTicker(reloadVerifyInterval)
+	defer ticker.Stop()
+
+	var reason string
+	for {
+		applied, r, failed, err := check(ctx)
+		if r != "" {
+			reason = r
+		}
+		switch {
+		case applied:
+			return nil
+		case failed:
+			return &ReloadNotAppliedError{Service: service, Reason: reason, Err: err}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			if reason == "" {
+				reason = "timed out waiting for the configuration"
+			}
+			return &ReloadNotAppliedError{Service: service, Reason: reason, Err: err}
+		case <-ticker.C:
+		}
+	}
+}
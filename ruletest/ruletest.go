@@ -0,0 +1,297 @@
+// Package ruletest unit tests Prometheus rule groups the way "promtool test
+// rules" does, without a running Prometheus. It is separate from pag because
+// the rule manager and the promql test storage it embeds pull in the service
+// discovery packages of Prometheus, with client-go and the AWS, Azure and GCP
+// SDKs, which pag's own rule validation with promql and its parser does not need.
+package ruletest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/promql/promqltest"
+	"github.com/prometheus/prometheus/rules"
+	"github.com/vine-io/pag"
+)
+
+// Series is an input series in the promtool input_series notation,
+// e.g. Series `up{job="node"}` with Values "1 1 0x10".
+type Series struct {
+	Series string `json:"series"`
+	Values string `json:"values"`
+}
+
+// Alert is a firing alert expected by a Case. The alertname label is implied
+// and does not have to be given.
+type Alert struct {
+	Labels      map[string]string `json:"exp_labels,omitempty"`
+	Annotations map[string]string `json:"exp_annotations,omitempty"`
+}
+
+// Case lists the alerts of Alertname expected to fire at EvalTime.
+type Case struct {
+	EvalTime  string  `json:"eval_time"`
+	Alertname string  `json:"alertname"`
+	ExpAlerts []Alert `json:"exp_alerts"`
+}
+
+// Test is the equivalent of a promtool test group.
+type Test struct {
+	// Interval between the values of the input series, 1m by default.
+	Interval string `json:"interval,omitempty"`
+	// EvaluationInterval is how often the rule groups are evaluated, 1m by default.
+	EvaluationInterval string `json:"evaluation_interval,omitempty"`
+
+	InputSeries    []Series          `json:"input_series"`
+	AlertRuleTests []Case            `json:"alert_rule_test"`
+	ExternalLabels map[string]string `json:"external_labels,omitempty"`
+	ExternalURL    string            `json:"external_url,omitempty"`
+}
+
+// Failure is a Case whose alerts did not match.
+type Failure struct {
+	EvalTime  string
+	Alertname string
+	Expected  []Alert
+	Got       []Alert
+	// Diff is a human readable diff of Expected (-) and Got (+).
+	Diff string
+}
+
+func (f *Failure) String() string {
+	return fmt.Sprintf("alertname: %s, time: %s\n%s", f.Alertname, f.EvalTime, f.Diff)
+}
+
+// Run evaluates groups against the input series of test the way "promtool
+// test rules" does, with an embedded PromQL engine and storage, and returns
+// the test cases whose alerts did not match. The error is set when the
+// test or the groups are invalid or a rule fails to evaluate.
+func Run(ctx context.Context, test *Test, groups ...pag.RuleGroup) ([]Failure, error) {
+	interval, err := parseDuration(test.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("interval: %w", err)
+	}
+	evalInterval, err := parseDuration(test.EvaluationInterval)
+	if err != nil {
+		return nil, fmt.Errorf("evaluation_interval: %w", err)
+	}
+
+	evalTimes := map[time.Duration][]Case{}
+	var maxEvalTime time.Duration
+	for _, tc := range test.AlertRuleTests {
+		if tc.Alertname == "" {
+			return nil, fmt.Errorf("alert rule test at eval_time %s: alertname is required", tc.EvalTime)
+		}
+		d, err := model.ParseDuration(tc.EvalTime)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule test %q: eval_time: %w", tc.Alertname, err)
+		}
+		evalTimes[time.Duration(d)] = append(evalTimes[time.Duration(d)], tc)
+		if time.Duration(d) > maxEvalTime {
+			maxEvalTime = time.Duration(d)
+		}
+	}
+
+	var load strings.Builder
+	fmt.Fprintf(&load, "load %s\n", model.Duration(interval))
+	for _, s := range test.InputSeries {
+		fmt.Fprintf(&load, "  %s %s\n", s.Series, s.Values)
+	}
+	suite, err := promqltest.NewLazyLoader(load.String(), promqltest.LazyLoaderOpts{
+		EnableAtModifier:     true,
+		EnableNegativeOffset: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("input_series: %w", err)
+	}
+	defer suite.Close()
+	suite.SubqueryInterval = evalInterval
+
+	opts := &rules.ManagerOptions{
+		QueryFunc:  rules.EngineQueryFunc(suite.QueryEngine(), suite.Storage()),
+		Appendable: suite.Storage(),
+		Context:    ctx,
+		NotifyFunc: func(ctx context.Context, expr string, alerts ...*rules.Alert) {},
+		Logger:     log.NewNopLogger(),
+		Metrics:    rules.NewGroupMetrics(nil),
+	}
+	evalGroups := make([]*rules.Group, 0, len(groups))
+	for i := range groups {
+		g, err := newGroup(&groups[i], test, evalInterval, opts)
+		if err != nil {
+			return nil, err
+		}
+		evalGroups = append(evalGroups, g)
+	}
+
+	start := time.Unix(0, 0).UTC()
+	var failures []Failure
+	for ts := time.Duration(0); ts <= maxEvalTime; ts += evalInterval {
+		var evalErr error
+		suite.WithSamplesTill(start.Add(ts), func(err error) {
+			if err != nil {
+				evalErr = err
+				return
+			}
+			for _, g := range evalGroups {
+				g.Eval(ctx, start.Add(ts))
+				for _, r := range g.Rules() {
+					if err := r.LastError(); err != nil {
+						evalErr = errors.Join(evalErr, fmt.Errorf("rule %s, time %s: %w", r.Name(), model.Duration(ts), err))
+					}
+				}
+			}
+		})
+		if evalErr != nil {
+			return nil, evalErr
+		}
+
+		// the test cases between this and the next evaluation see the alerts of this one
+		for t, cases := range evalTimes {
+			if t < ts || t >= ts+evalInterval {
+				continue
+			}
+			for _, tc := range cases {
+				if f := checkAlerts(evalGroups, tc); f != nil {
+					failures = append(failures, *f)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(failures, func(i, j int) bool {
+		a, _ := model.ParseDuration(failures[i].EvalTime)
+		b, _ := model.ParseDuration(failures[j].EvalTime)
+		return a < b
+	})
+	return failures, nil
+}
+
+func newGroup(rg *pag.RuleGroup, test *Test, interval time.Duration, opts *rules.ManagerOptions) (*rules.Group, error) {
+	if err := rg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var queryOffset *time.Duration
+	if rg.QueryOffset != "" {
+		d, _ := model.ParseDuration(rg.QueryOffset)
+		queryOffset = (*time.Duration)(&d)
+	}
+
+	externalLabels := labels.FromMap(test.ExternalLabels)
+	rs := make([]rules.Rule, 0, len(rg.Rules))
+	for _, r := range rg.Rules {
+		expr, _ := parser.ParseExpr(r.Expr)
+		if r.Record != "" {
+			rs = append(rs, rules.NewRecordingRule(r.Record, expr, labels.FromMap(r.Labels)))
+			continue
+		}
+
+		var hold, keepFiringFor model.Duration
+		if r.For != "" {
+			hold, _ = model.ParseDuration(r.For)
+		}
+		if r.KeepFiringFor != "" {
+			keepFiringFor, _ = model.ParseDuration(r.KeepFiringFor)
+		}
+		// restored, so the ALERTS series is written from the first evaluation
+		rs = append(rs, rules.NewAlertingRule(
+			r.Alert, expr, time.Duration(hold), time.Duration(keepFiringFor),
+			labels.FromMap(r.Labels), labels.FromMap(r.Annotations), externalLabels, test.ExternalURL,
+			true, opts.Logger,
+		))
+	}
+
+	return rules.NewGroup(rules.GroupOptions{
+		Name:        rg.Name,
+		Interval:    interval,
+		Limit:       rg.Limit,
+		Rules:       rs,
+		Opts:        opts,
+		QueryOffset: queryOffset,
+	}), nil
+}
+
+func checkAlerts(groups []*rules.Group, tc Case) *Failure {
+	var got []Alert
+	for _, g := range groups {
+		for _, r := range g.Rules() {
+			ar, ok := r.(*rules.AlertingRule)
+			if !ok || ar.Name() != tc.Alertname {
+				continue
+			}
+			for _, a := range ar.ActiveAlerts() {
+				if a.State != rules.StateFiring {
+					continue
+				}
+				ls := a.Labels.Map()
+				delete(ls, labels.AlertName)
+				got = append(got, Alert{Labels: ls, Annotations: a.Annotations.Map()})
+			}
+		}
+	}
+
+	expected := append([]Alert(nil), tc.ExpAlerts...)
+	sortAlerts(expected)
+	sortAlerts(got)
+
+	diff := cmp.Diff(normalizeAlerts(expected), normalizeAlerts(got))
+	if diff == "" {
+		return nil
+	}
+	return &Failure{
+		EvalTime:  tc.EvalTime,
+		Alertname: tc.Alertname,
+		Expected:  expected,
+		Got:       got,
+		Diff:      diff,
+	}
+}
+
+// normalizeAlerts makes nil and empty label sets compare equal.
+func normalizeAlerts(alerts []Alert) []Alert {
+	out := make([]Alert, 0, len(alerts))
+	for _, a := range alerts {
+		n := Alert{Labels: map[string]string{}, Annotations: map[string]string{}}
+		for k, v := range a.Labels {
+			n.Labels[k] = v
+		}
+		for k, v := range a.Annotations {
+			n.Annotations[k] = v
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func sortAlerts(alerts []Alert) {
+	sort.SliceStable(alerts, func(i, j int) bool {
+		if c := labels.Compare(labels.FromMap(alerts[i].Labels), labels.FromMap(alerts[j].Labels)); c != 0 {
+			return c < 0
+		}
+		return labels.Compare(labels.FromMap(alerts[i].Annotations), labels.FromMap(alerts[j].Annotations)) < 0
+	})
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return time.Minute, nil
+	}
+	d, err := model.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, errors.New("must be greater than zero")
+	}
+	return time.Duration(d), nil
+}
@@ -0,0 +1,91 @@
+package ruletest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vine-io/pag"
+)
+
+func testRuleGroup() pag.RuleGroup {
+	return pag.RuleGroup{
+		Name: "node",
+		Rules: []pag.Rule{
+			{Record: "job:up:sum", Expr: `sum by (job) (up)`},
+			{
+				Alert:       "InstanceDown",
+				Expr:        `up == 0`,
+				For:         "5m",
+				Labels:      map[string]string{"severity": "page"},
+				Annotations: map[string]string{"summary": "{{ $labels.instance }} down"},
+			},
+			{Alert: "JobDown", Expr: `job:up:sum == 0`},
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	test := &Test{
+		InputSeries: []Series{
+			{Series: `up{job="node", instance="a"}`, Values: "1 1 0x10"},
+			{Series: `up{job="node", instance="b"}`, Values: "1x12"},
+		},
+		AlertRuleTests: []Case{
+			{EvalTime: "5m", Alertname: "InstanceDown"},
+			{
+				EvalTime:  "8m",
+				Alertname: "InstanceDown",
+				ExpAlerts: []Alert{{
+					Labels:      map[string]string{"job": "node", "instance": "a", "severity": "page"},
+					Annotations: map[string]string{"summary": "a down"},
+				}},
+			},
+			{EvalTime: "10m", Alertname: "JobDown"},
+		},
+	}
+
+	failures, err := Run(context.TODO(), test, testRuleGroup())
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+
+	test.AlertRuleTests = []Case{
+		{EvalTime: "2m", Alertname: "InstanceDown", ExpAlerts: []Alert{{
+			Labels: map[string]string{"job": "node", "instance": "a", "severity": "page"},
+		}}},
+		{EvalTime: "10m", Alertname: "InstanceDown"},
+	}
+	failures, err = Run(context.TODO(), test, testRuleGroup())
+	assert.NoError(t, err)
+	if assert.Len(t, failures, 2) {
+		assert.Equal(t, "2m", failures[0].EvalTime)
+		assert.Empty(t, failures[0].Got)
+		assert.Contains(t, failures[0].Diff, `"severity": "page"`)
+
+		assert.Equal(t, "10m", failures[1].EvalTime)
+		assert.Len(t, failures[1].Got, 1)
+		assert.Equal(t, "a down", failures[1].Got[0].Annotations["summary"])
+	}
+}
+
+func TestRunInvalid(t *testing.T) {
+	test := &Test{
+		InputSeries:    []Series{{Series: `up`, Values: "1x5"}},
+		AlertRuleTests: []Case{{EvalTime: "1m", Alertname: "InstanceDown"}},
+	}
+
+	rg := testRuleGroup()
+	rg.Rules[1].Expr = "up =="
+	_, err := Run(context.TODO(), test, rg)
+	var ruleErr *pag.RuleError
+	assert.ErrorAs(t, err, &ruleErr)
+
+	test.InputSeries[0].Values = "1 a"
+	_, err = Run(context.TODO(), test, testRuleGroup())
+	assert.Error(t, err)
+
+	test.InputSeries[0].Values = "1x5"
+	test.AlertRuleTests[0].Alertname = ""
+	_, err = Run(context.TODO(), test, testRuleGroup())
+	assert.Error(t, err)
+}
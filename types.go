@@ -2,25 +2,37 @@ package pag
 
 type ServiceDiscoveryEndpoint struct {
 	Targets []string          `json:"targets"`
-	Labels  map[string]string `json:"labels"`
+	Labels  map[string]string `json:"labels,omitempty"`
 }
 
 type ServiceDiscovery struct {
 	Name string `json:"name"`
 
+	// Job is the scrape job whose file_sd_configs the group is written for.
+	Job string `json:"job,omitempty"`
+
 	Endpoints []ServiceDiscoveryEndpoint `json:"endpoints"`
 }
 
+// Rule is either an alerting rule (Alert is set) or a recording rule (Record is set).
 type Rule struct {
-	Alert       string            `json:"alert"`
-	Expr        string            `json:"expr"`
-	For         string            `json:"for"`
-	Labels      map[string]string `json:"labels"`
-	Annotations map[string]string `json:"annotations"`
+	Record        string            `json:"record,omitempty"`
+	Alert         string            `json:"alert,omitempty"`
+	Expr          string            `json:"expr"`
+	For           string            `json:"for,omitempty"`
+	KeepFiringFor string            `json:"keep_firing_for,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
 }
 
 type RuleGroup struct {
-	Name  string `json:"name"`
+	Name string `json:"name"`
+	// How often rules in the group are evaluated, the global evaluation_interval by default.
+	Interval string `json:"interval,omitempty"`
+	// Offset the evaluation timestamp by this duration into the past.
+	QueryOffset string `json:"query_offset,omitempty"`
+	// Limit the number of alerts an alerting rule and series a recording rule can produce, 0 is no limit.
+	Limit int    `json:"limit,omitempty"`
 	Rules []Rule `json:"rules"`
 }
 
@@ -0,0 +1,196 @@
+package pag
+
+import (
+	"bytes"
+
+	yamlv3 "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// identityKeys are the keys used to pair up the items of a list when items
+// were added, removed or reordered.
+var identityKeys = []string{"name", "job_name", "alert", "record", "url"}
+
+// mergeYAML returns the original YAML document with the changes between base
+// and updated applied to it. base and updated are the typed models before and
+// after the change, so anything the models do not know about (unknown keys,
+// comments, key order, quoting) is kept from the original document.
+func mergeYAML(original []byte, base, updated any) ([]byte, error) {
+	newNode, err := toYAMLNode(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yamlv3.Node
+	if err = yamlv3.Unmarshal(original, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yamlv3.DocumentNode || len(doc.Content) == 0 {
+		return marshalYAML(updated)
+	}
+
+	baseNode, err := toYAMLNode(base)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Content[0] = mergeNode(doc.Content[0], baseNode, newNode)
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err = enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err = enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func toYAMLNode(v any) (*yamlv3.Node, error) {
+	data, err := marshalYAML(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yamlv3.Node
+	if err = yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return doc.Content[0], nil
+}
+
+// mergeNode merges the change from base to updated into old and returns the result.
+func mergeNode(old, base, updated *yamlv3.Node) *yamlv3.Node {
+	switch {
+	case updated == nil:
+		return nil
+	case old == nil:
+		return updated
+	case equalNode(base, updated):
+		return old
+	}
+
+	switch {
+	case updated.Kind == yamlv3.MappingNode && old.Kind == yamlv3.MappingNode:
+		return mergeMapping(old, base, updated)
+	case updated.Kind == yamlv3.SequenceNode && old.Kind == yamlv3.SequenceNode:
+		if base == nil || base.Kind != yamlv3.SequenceNode || len(base.Content) != len(old.Content) {
+			break
+		}
+		return mergeSequence(old, base, updated)
+	}
+
+	updated.HeadComment = old.HeadComment
+	updated.LineComment = old.LineComment
+	updated.FootComment = old.FootComment
+	return updated
+}
+
+func mergeMapping(old, base, updated *yamlv3.Node) *yamlv3.Node {
+	content := make([]*yamlv3.Node, 0, len(old.Content))
+	seen := map[string]bool{}
+
+	for i := 0; i+1 < len(old.Content); i += 2 {
+		key := old.Content[i].Value
+		value := mappingValue(updated, key)
+		if value == nil {
+			// keep the keys the model does not know about, drop the ones it removed
+			if mappingValue(base, key) == nil {
+				content = append(content, old.Content[i], old.Content[i+1])
+			}
+			continue
+		}
+
+		seen[key] = true
+		if merged := mergeNode(old.Content[i+1], mappingValue(base, key), value); merged != nil {
+			content = append(content, old.Content[i], merged)
+		}
+	}
+
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		key := updated.Content[i].Value
+		if seen[key] {
+			continue
+		}
+		// a default the model adds on its own is not a change
+		if baseValue := mappingValue(base, key); baseValue != nil && equalNode(baseValue, updated.Content[i+1]) {
+			continue
+		}
+		content = append(content, updated.Content[i], updated.Content[i+1])
+	}
+
+	old.Content = content
+	return old
+}
+
+func mergeSequence(old, base, updated *yamlv3.Node) *yamlv3.Node {
+	used := make([]bool, len(base.Content))
+	match := func(item *yamlv3.Node, j int) int {
+		for i, b := range base.Content {
+			if !used[i] && equalNode(b, item) {
+				return i
+			}
+		}
+		for _, key := range identityKeys {
+			id := mappingValue(item, key)
+			if id == nil || id.Kind != yamlv3.ScalarNode {
+				continue
+			}
+			for i, b := range base.Content {
+				if v := mappingValue(b, key); !used[i] && v != nil && v.Value == id.Value {
+					return i
+				}
+			}
+		}
+		if len(base.Content) == len(updated.Content) && !used[j] {
+			return j
+		}
+		return -1
+	}
+
+	content := make([]*yamlv3.Node, 0, len(updated.Content))
+	for j, item := range updated.Content {
+		i := match(item, j)
+		if i < 0 {
+			content = append(content, item)
+			continue
+		}
+		used[i] = true
+		content = append(content, mergeNode(old.Content[i], base.Content[i], item))
+	}
+
+	old.Content = content
+	return old
+}
+
+func mappingValue(n *yamlv3.Node, key string) *yamlv3.Node {
+	if n == nil || n.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func equalNode(a, b *yamlv3.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !equalNode(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
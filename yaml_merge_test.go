@@ -0,0 +1,101 @@
+package pag
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/common/config"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestMergeYAML(t *testing.T) {
+	original := []byte(`# my config
+global:
+  scrape_interval: 1m # every minute
+  unknown_global: keep
+storage:
+  tsdb:
+    out_of_order_time_window: 10m
+scrape_configs:
+  - job_name: 'a'
+    sample_limit: 100
+    static_configs:
+      - targets: ['127.0.0.1:1']
+  - job_name: 'b'
+    static_configs:
+      - targets: ['127.0.0.1:2']
+`)
+
+	var base PrometheusYAML
+	if !assert.NoError(t, yaml.Unmarshal(original, &base)) {
+		return
+	}
+	updated, err := base.clone()
+	if !assert.NoError(t, err) {
+		return
+	}
+	updated.ScrapeConfigs = append([]PrometheusScrapeConfigYAML{{JobName: "c"}}, updated.ScrapeConfigs[1], updated.ScrapeConfigs[0])
+	updated.ScrapeConfigs[1].StaticConfig[0].Targets = []string{"127.0.0.1:3"}
+	updated.RuleFiles = []string{"rules/*.yml"}
+
+	out, err := mergeYAML(original, &base, updated)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `# my config
+global:
+  scrape_interval: 1m # every minute
+  unknown_global: keep
+storage:
+  tsdb:
+    out_of_order_time_window: 10m
+scrape_configs:
+  - job_name: c
+  - job_name: 'b'
+    static_configs:
+      - targets: ['127.0.0.1:3']
+  - job_name: 'a'
+    sample_limit: 100
+    static_configs:
+      - targets: ['127.0.0.1:1']
+rule_files:
+  - rules/*.yml
+`, string(out))
+
+	out, err = mergeYAML(original, &base, &base)
+	assert.NoError(t, err)
+	assert.Equal(t, string(original), string(out))
+}
+
+func TestMarshalYAMLSecrets(t *testing.T) {
+	sc := PrometheusScrapeConfigYAML{
+		JobName: "consul",
+		ConsulSDConfigs: []ConsulSDConfig{{
+			Token: "consul-token",
+			HTTPClientConfig: config.HTTPClientConfig{
+				HTTPHeaders: &config.Headers{Headers: map[string]config.Header{
+					"X-Api-Key": {Secrets: []config.Secret{"header-secret"}},
+				}},
+				ProxyConfig: config.ProxyConfig{
+					ProxyURL:           config.URL{URL: &url.URL{Scheme: "http", Host: "proxy:3128"}},
+					ProxyConnectHeader: config.ProxyHeader{"Proxy-Authorization": {"proxy-secret"}},
+				},
+			},
+		}},
+	}
+
+	out, err := marshalYAML(&sc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(out), "token: consul-token")
+	assert.Contains(t, string(out), "X-Api-Key:\n      secrets:\n      - header-secret")
+	assert.Contains(t, string(out), "Proxy-Authorization:\n    - proxy-secret")
+	assert.NotContains(t, string(out), "<secret>")
+
+	// the process-wide setting of prometheus/common is left alone
+	assert.False(t, config.MarshalSecretValue)
+	data, _ := yaml.Marshal(&sc)
+	assert.NotContains(t, string(data), "consul-token")
+}